@@ -2,7 +2,10 @@ module video-chopper
 
 go 1.24.1
 
-require github.com/kkdai/youtube/v2 v2.10.5
+require (
+	github.com/kkdai/youtube/v2 v2.10.5
+	golang.org/x/text v0.22.0
+)
 
 require (
 	github.com/bitly/go-simplejson v0.5.1 // indirect
@@ -10,5 +13,4 @@ require (
 	github.com/dop251/goja v0.0.0-20250125213203-5ef83b82af17 // indirect
 	github.com/go-sourcemap/sourcemap v2.1.4+incompatible // indirect
 	github.com/google/pprof v0.0.0-20250208200701-d0013a598941 // indirect
-	golang.org/x/text v0.22.0 // indirect
 )
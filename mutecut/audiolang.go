@@ -0,0 +1,51 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selectAudioByLanguage finds the audio stream tagged with cfg.AudioLang and
+// records it in cfg.AudioMap so only that track is carried into the output.
+func selectAudioByLanguage(cfg *Config) error {
+	if cfg.AudioLang == "" {
+		return nil
+	}
+
+	streams, err := probeStreams(*cfg)
+	if err != nil {
+		return err
+	}
+
+	var available []string
+	for _, s := range streams {
+		if s.CodecType != "audio" {
+			continue
+		}
+		lang := s.Tags["language"]
+		if lang == "" {
+			lang = "und"
+		}
+		if matchesLanguage(lang, cfg.AudioLang) {
+			cfg.AudioMap = fmt.Sprintf("0:%d", s.Index)
+			logf("Using audio stream #%d (%s)\n", s.Index, lang)
+			return nil
+		}
+		available = append(available, fmt.Sprintf("#%d=%s", s.Index, lang))
+	}
+
+	if len(available) == 0 {
+		return fmt.Errorf("input has no audio streams")
+	}
+	return fmt.Errorf("no audio stream with language '%s' (available: %s)", cfg.AudioLang, strings.Join(available, ", "))
+}
+
+// matchesLanguage compares ISO 639 codes loosely, so "en" also finds "eng".
+func matchesLanguage(tag, want string) bool {
+	tag = strings.ToLower(tag)
+	want = strings.ToLower(want)
+	if tag == want {
+		return true
+	}
+	return len(want) == 2 && len(tag) == 3 && strings.HasPrefix(tag, want)
+}
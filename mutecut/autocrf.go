@@ -0,0 +1,111 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const autoCRFSampleSeconds = 5.0
+
+// autoCRFBands maps sample complexity (bits per pixel per frame of a fast
+// CRF 23 encode) to the CRF to use. Simple content such as screencasts gets
+// a lower CRF to keep text crisp; busy footage gets a higher one so it
+// doesn't balloon in size.
+var autoCRFBands = []struct {
+	maxBPP float64
+	crf    int
+}{
+	{0.02, 20},
+	{0.05, 22},
+	{0.10, 23},
+	{0.20, 25},
+}
+
+const autoCRFBusiest = 27
+
+// chooseAutoCRF encodes a short sample from a quarter of the way in and
+// picks a CRF from how hard it was to compress.
+func chooseAutoCRF(cfg Config) (int, error) {
+	width, height, fps, err := probeVideoGeometry(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	offset := 0.0
+	if duration, err := GetDuration(cfg); err == nil && duration > autoCRFSampleSeconds*2 {
+		offset = duration / 4
+	}
+
+	tmp, err := os.CreateTemp("", "mutecut-autocrf-*.mp4")
+	if err != nil {
+		return 0, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	args := []string{
+		"-loglevel", "error",
+		"-ss", fmt.Sprintf("%.3f", offset),
+		"-t", fmt.Sprintf("%.3f", autoCRFSampleSeconds),
+	}
+	args = append(args, inputSourceArgs(cfg)...)
+	args = append(args,
+		"-an",
+		"-c:v", "libx264", "-preset", "ultrafast", "-crf", "23",
+		"-y", tmp.Name(),
+	)
+	if err := runFFmpeg(cfg, args); err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return 0, err
+	}
+	bpp := float64(info.Size()*8) / (float64(width*height) * fps * autoCRFSampleSeconds)
+
+	for _, band := range autoCRFBands {
+		if bpp <= band.maxBPP {
+			return band.crf, nil
+		}
+	}
+	return autoCRFBusiest, nil
+}
+
+// probeVideoGeometry returns the first video stream's size and frame rate.
+func probeVideoGeometry(cfg Config) (int, int, float64, error) {
+	streams, err := probeStreams(cfg)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, s := range streams {
+		if s.CodecType != "video" {
+			continue
+		}
+		fps := parseFrameRate(s.FrameRate)
+		if s.Width == 0 || s.Height == 0 || fps == 0 {
+			return 0, 0, 0, fmt.Errorf("could not read video size/frame rate")
+		}
+		return s.Width, s.Height, fps, nil
+	}
+	return 0, 0, 0, fmt.Errorf("input has no video stream")
+}
+
+// parseFrameRate parses ffprobe's rational frame rates such as "30000/1001".
+func parseFrameRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !found {
+		return n
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
@@ -0,0 +1,113 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseSilenceThreshold reads a -silence-threshold value such as "-30dB"
+// or "-30".
+func parseSilenceThreshold(value string) (float64, error) {
+	trimmed := strings.TrimSpace(value)
+	trimmed = strings.TrimSuffix(strings.TrimSuffix(trimmed, "dB"), "db")
+	db, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || db > 0 {
+		return 0, fmt.Errorf("invalid -silence-threshold '%s' (use a level like -30dB)", value)
+	}
+	return db, nil
+}
+
+// validateAutoMute rejects -auto-mute/-trim-silence where they can't apply:
+// only the main cut goes through the filters that act on the silences.
+func validateAutoMute(cfg Config) error {
+	if !cfg.AutoMute {
+		return nil
+	}
+	switch {
+	case cfg.ExtractMP3, cfg.ExportWebP, cfg.ExportGIF, cfg.SplitSilence, cfg.Repair, len(cfg.ConcatFiles) > 0:
+		return fmt.Errorf("-auto-mute only applies to a regular cut")
+	case cfg.TrimSilence && cfg.RemoveStart != "":
+		return fmt.Errorf("use either -trim-silence or -remove-start/-remove-end, not both")
+	}
+	return nil
+}
+
+// applyAutoMute finds the silent stretches inside the trim window and either
+// adds them to the muted ranges or, with -trim-silence, marks them to be cut
+// out. Both are kept on the input's timeline like the other ranges.
+func applyAutoMute(cfg *Config) error {
+	silences, err := detectSilences(*cfg, cfg.SilenceMin, cfg.SilenceNoise)
+	if err != nil {
+		return err
+	}
+
+	from, to := 0.0, 0.0
+	if cfg.StartTime != "" {
+		from = toSeconds(cfg.StartTime)
+	}
+	switch {
+	case cfg.EndTime != "":
+		to = toSeconds(cfg.EndTime)
+	case cfg.Duration != "":
+		to = from + toSeconds(cfg.Duration)
+	default:
+		duration, err := GetDuration(*cfg)
+		if err != nil {
+			return err
+		}
+		to = duration
+	}
+
+	var found []Segment
+	total := 0.0
+	for _, s := range silences {
+		end := s.End
+		if end < 0 {
+			end = to // Silent until the end of the input
+		}
+		seg := Segment{Start: max(s.Start, from), End: min(end, to)}
+		if seg.End > seg.Start {
+			found = append(found, seg)
+			total += seg.End - seg.Start
+		}
+	}
+	if len(found) == 0 {
+		logln("No silent stretches found, nothing to do.")
+		return nil
+	}
+
+	if cfg.TrimSilence {
+		if to-from-total < minSplitPiece {
+			return fmt.Errorf("the input is silent throughout, -trim-silence would leave nothing")
+		}
+		logf("Cutting %d silent stretches (%s in total)\n", len(found), formatTimestamp(total))
+		cfg.SilenceCuts = found
+	} else {
+		logf("Muting %d silent stretches (%s in total)\n", len(found), formatTimestamp(total))
+		cfg.MuteSegments = append(cfg.MuteSegments, found...)
+	}
+	return nil
+}
+
+// silenceCutFilters drop the given ranges (already on the output's
+// timeline) from the video and audio and close up the gaps.
+func silenceCutFilters(cuts []Segment) (video, audio string) {
+	ranges := make([]string, len(cuts))
+	for i, c := range cuts {
+		ranges[i] = fmt.Sprintf("between(t,%.3f,%.3f)", c.Start, c.End)
+	}
+	drop := strings.Join(ranges, "+")
+	video = fmt.Sprintf("select='not(%s)',setpts=N/FRAME_RATE/TB", drop)
+	audio = fmt.Sprintf("aselect='not(%s)',asetpts=N/SR/TB", drop)
+	return video, audio
+}
+
+// silenceCutSeconds is how much shorter -trim-silence makes the output.
+func silenceCutSeconds(cfg Config) float64 {
+	total := 0.0
+	for _, c := range cfg.SilenceCuts {
+		total += c.End - c.Start
+	}
+	return total
+}
@@ -0,0 +1,358 @@
+package mutecut
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var videoExtensions = map[string]bool{
+	".mp4": true, ".m4v": true, ".mov": true, ".mkv": true, ".webm": true,
+	".avi": true, ".wmv": true, ".flv": true, ".ts": true, ".mpg": true, ".mpeg": true,
+}
+
+// batchFlags are handled by the batch runner itself and not passed on to
+// the per-file runs. The batch holds the -single-instance lock for all of
+// its files, so the runs it starts mustn't try to take it again.
+var batchFlags = map[string]bool{
+	"batch": true, "jobs": true, "recursive": true, "i": true, "o": true,
+	"single-instance": true, "single-instance-wait": true,
+}
+
+// batchOptions are the -batch settings the runner itself acts on; the rest
+// of the command line is passed on to each file's run.
+type batchOptions struct {
+	Target    string // Directory or glob
+	OutputDir string
+	Jobs      int
+	Recursive bool
+	Hash      bool // Collect each file's hashes into a SHA256SUMS manifest
+}
+
+// batchResult is the outcome of processing one file.
+type batchResult struct {
+	Input   string
+	Err     error
+	Elapsed time.Duration
+	Hashes  map[string]string
+}
+
+// batchStopGrace is how long a cancelled run gets to clean up before it's
+// killed.
+const batchStopGrace = 10 * time.Second
+
+// resultFileEnv names the file a batch child writes its -json summary to,
+// which is how the hashes get back to the batch.
+const resultFileEnv = "MUTECUT_RESULT_FILE"
+
+// writeResultFile saves the run's summary for the batch that started it.
+func writeResultFile(path string, cfg Config, result Result) error {
+	var size int64
+	if info, err := os.Stat(result.Output); err == nil {
+		size = info.Size()
+	}
+	data, err := json.Marshal(newJobSummary(cfg, result, size))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// readResultFile loads what a child saved with writeResultFile.
+func readResultFile(path string) (jobSummary, error) {
+	var summary jobSummary
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &summary)
+	}
+	return summary, err
+}
+
+// collectBatchInputs lists the video files a -batch value refers to: every
+// video in a directory (and its subdirectories when recursive, leaving out
+// skipDir), or every video matching a glob.
+func collectBatchInputs(target string, recursive bool, skipDir string) ([]string, error) {
+	var candidates []string
+	if info, err := os.Stat(target); err == nil && info.IsDir() && recursive {
+		err := filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != target && samePath(path, skipDir) {
+					return filepath.SkipDir // Don't reprocess our own outputs
+				}
+				return nil
+			}
+			candidates = append(candidates, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if err == nil && info.IsDir() {
+		entries, err := os.ReadDir(target)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				candidates = append(candidates, filepath.Join(target, e.Name()))
+			}
+		}
+	} else {
+		matches, err := filepath.Glob(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -batch pattern: %w", err)
+		}
+		candidates = matches
+	}
+
+	var inputs []string
+	for _, path := range candidates {
+		if videoExtensions[strings.ToLower(filepath.Ext(path))] {
+			inputs = append(inputs, path)
+		}
+	}
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+// childArgs rebuilds the command line for one file of the batch: the
+// original flags minus the batch-only ones, pointed at input.
+func childArgs(args []string, input, outputDir string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		f := flag.Lookup(name)
+		if !strings.HasPrefix(args[i], "-") || f == nil {
+			out = append(out, args[i])
+			continue
+		}
+		takesValue := !hasValue && !isBoolFlag(f)
+		if batchFlags[name] || name == "output-dir" {
+			if takesValue {
+				i++
+			}
+			continue
+		}
+		out = append(out, args[i])
+		if takesValue && i+1 < len(args) {
+			i++
+			out = append(out, args[i])
+		}
+	}
+	return append(out, "-i", input, "-output-dir", outputDir)
+}
+
+// samePath reports whether a and b name the same directory.
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// batchOutputDir is where input's output goes: outputDir itself, or with
+// -recursive the same subdirectory under outputDir that input is in under
+// the -batch directory.
+func batchOutputDir(target, input, outputDir string, recursive bool) string {
+	if !recursive {
+		return outputDir
+	}
+	rel, err := filepath.Rel(target, filepath.Dir(input))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return outputDir
+	}
+	return filepath.Join(outputDir, rel)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// runBatch processes every video in target with the same settings, one
+// child run per file so a failure doesn't stop the rest. Up to jobs files
+// are encoded at once. It prints a summary and returns the exit code for the
+// whole batch. With recursive, the -batch directory's subfolders are
+// processed too and their layout is mirrored under outputDir.
+func runBatch(ctx context.Context, opts batchOptions) int {
+	target, outputDir, jobs, recursive := opts.Target, opts.OutputDir, opts.Jobs, opts.Recursive
+	if jobs < 1 {
+		errorln("Error: -jobs must be at least 1.")
+		return 1
+	}
+	if outputDir == "" {
+		dir := target
+		if info, err := os.Stat(target); err != nil || !info.IsDir() {
+			dir = filepath.Dir(target)
+		}
+		outputDir = filepath.Join(dir, "cleaned")
+	}
+	inputs, err := collectBatchInputs(target, recursive, outputDir)
+	if err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if len(inputs) == 0 {
+		errorf("Error: no video files found in '%s'.\n", target)
+		return 1
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		errorf("Error: cannot locate own executable: %v\n", err)
+		return 1
+	}
+
+	logf("Batch: %d files -> %s (%d at a time)\n", len(inputs), outputDir, jobs)
+	results := make([]batchResult, len(inputs))
+	work := make(chan int)
+	var printMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				dir := batchOutputDir(target, inputs[i], outputDir, recursive)
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					results[i] = batchResult{Input: inputs[i], Err: err}
+					continue
+				}
+				args := childArgs(os.Args[1:], inputs[i], dir)
+				header := fmt.Sprintf("\n=== [%d/%d] %s ===\n", i+1, len(inputs), inputs[i])
+				cmd := exec.CommandContext(ctx, self, args...)
+				// On cancel, interrupt rather than kill the run so it stops its
+				// ffmpeg and removes its partial output, as on Ctrl+C
+				cmd.Cancel = func() error {
+					if err := cmd.Process.Signal(os.Interrupt); err != nil {
+						return cmd.Process.Kill() // No interrupt on Windows
+					}
+					return nil
+				}
+				cmd.WaitDelay = batchStopGrace
+
+				// Parallel runs would interleave their output, so each
+				// file's log is held back and printed whole when it ends.
+				var output bytes.Buffer
+				stderr := &tailBuffer{max: ffmpegErrorTail}
+				if jobs == 1 {
+					logf("%s", header)
+					cmd.Stdin, cmd.Stdout = os.Stdin, os.Stdout
+					cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+				} else {
+					cmd.Args = append(cmd.Args, "-stats-interval", "1m") // Keep the held-back logs short
+					cmd.Stdout, cmd.Stderr = &output, io.MultiWriter(&output, stderr)
+				}
+
+				var resultFile string
+				if opts.Hash {
+					if f, err := os.CreateTemp("", "mutecut-result-*.json"); err == nil {
+						resultFile = f.Name()
+						f.Close()
+						cmd.Env = append(os.Environ(), resultFileEnv+"="+resultFile)
+					}
+				}
+
+				began := time.Now()
+				err := cmd.Run()
+				if err != nil && ctx.Err() == nil {
+					if msg := lastErrorLine(stderr.String()); msg != "" {
+						err = fmt.Errorf("%s (%v)", msg, err)
+					}
+				}
+				results[i] = batchResult{Input: inputs[i], Err: err, Elapsed: time.Since(began)}
+				if resultFile != "" {
+					if summary, err := readResultFile(resultFile); err == nil {
+						results[i].Hashes = summary.SHA256
+					}
+					os.Remove(resultFile)
+				}
+
+				if jobs > 1 {
+					printMu.Lock()
+					logf("%s", header)
+					os.Stdout.Write(output.Bytes())
+					printMu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range inputs {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	code := printBatchSummary(results)
+	if opts.Hash {
+		hashes := map[string]string{}
+		for _, r := range results {
+			maps.Copy(hashes, r.Hashes)
+		}
+		manifest, err := writeHashManifest(outputDir, hashes)
+		if err != nil {
+			errorf("Error: cannot write hash manifest: %v\n", err)
+			return 1
+		}
+		logf("Hashes of %d outputs written to: %s\n", len(hashes), manifest)
+	}
+	return code
+}
+
+// lastErrorLine picks the reason a child failed out of the end of its
+// stderr: the last "Error:" line, or failing that the last non-empty one.
+func lastErrorLine(stderr string) string {
+	lines := strings.Split(strings.ReplaceAll(stderr, "\r", "\n"), "\n")
+	last := ""
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if msg, ok := strings.CutPrefix(line, "Error:"); ok {
+			return strings.TrimSpace(msg)
+		}
+		if last == "" {
+			last = line
+		}
+	}
+	return last
+}
+
+// printBatchSummary lists each file's outcome and returns 1 if any failed.
+func printBatchSummary(results []batchResult) int {
+	failed := 0
+	logln("\nBatch summary:")
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			errorf("  FAILED  %s  [%s]: %v\n", r.Input, r.Elapsed.Round(time.Second), r.Err)
+			continue
+		}
+		logf("  OK      %s  [%s]\n", r.Input, r.Elapsed.Round(time.Second))
+	}
+	logf("%d succeeded, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package mutecut
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeChildEnv makes the test binary stand in for a batch's per-file run.
+const fakeChildEnv = "MUTECUT_TEST_FAKE_CHILD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeChildEnv) != "" {
+		fakeBatchChild()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeBatchChild writes a partial output and, like a real run, removes it
+// when interrupted.
+func fakeBatchChild() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var dir string
+	for i, arg := range os.Args {
+		if arg == "-output-dir" && i+1 < len(os.Args) {
+			dir = os.Args[i+1]
+		}
+	}
+	tmp := filepath.Join(dir, "a.tmp.mp4")
+	os.WriteFile(tmp, nil, 0o644)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Minute):
+	}
+	os.Remove(tmp)
+	os.Exit(1)
+}
+
+func TestLastErrorLine(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   string
+	}{
+		{"Processing...\nError: -crf must be between 0 and 51\n", "-crf must be between 0 and 51"},
+		{"Error: first\nprogress 50%\rprogress 100%\nError: second\n\n", "second"},
+		{"something went wrong\n", "something went wrong"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := lastErrorLine(tt.stderr); got != tt.want {
+			t.Errorf("lastErrorLine(%q) = %q, want %q", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestChildArgsDropsSingleInstance(t *testing.T) {
+	// Normally defined by Main
+	for _, name := range []string{"batch", "jobs", "crf"} {
+		if flag.Lookup(name) == nil {
+			flag.String(name, "", "")
+		}
+	}
+	for _, name := range []string{"single-instance", "single-instance-wait"} {
+		if flag.Lookup(name) == nil {
+			flag.Bool(name, false, "")
+		}
+	}
+
+	args := childArgs([]string{"-batch", "in", "-jobs", "4", "-single-instance", "-single-instance-wait=true", "-crf", "20"}, "in/a.mp4", "out")
+	want := []string{"-crf", "20", "-i", "in/a.mp4", "-output-dir", "out"}
+	if !slices.Equal(args, want) {
+		t.Errorf("childArgs = %q, want %q", args, want)
+	}
+}
+
+func TestRunBatchCancelLetsChildClean(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs os.Interrupt to be deliverable to a child")
+	}
+	t.Setenv(fakeChildEnv, "1")
+	in, out := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(in, "a.mp4"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int)
+	go func() { done <- runBatch(ctx, batchOptions{Target: in, OutputDir: out, Jobs: 1}) }()
+
+	tmp := filepath.Join(out, "a.tmp.mp4")
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(tmp); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the batch's run never started")
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(batchStopGrace + 5*time.Second):
+		t.Fatal("runBatch didn't return after cancel")
+	}
+	if left, _ := filepath.Glob(filepath.Join(out, "*.tmp*")); len(left) > 0 {
+		t.Errorf("partial outputs left behind: %v", left)
+	}
+}
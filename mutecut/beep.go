@@ -0,0 +1,36 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultBeepFreq is the classic broadcast censor tone.
+const defaultBeepFreq = 1000.0
+
+func validateBeep(cfg Config) error {
+	if !cfg.Beep {
+		return nil
+	}
+	if cfg.MuteStart == "" && len(cfg.MuteSegments) == 0 && (!cfg.AutoMute || cfg.TrimSilence) {
+		return fmt.Errorf("-beep needs something to mute (-mute-start/-mute-end, -mute-subs or -auto-mute)")
+	}
+	if cfg.BeepFreq <= 0 || cfg.BeepFreq > 20000 {
+		return fmt.Errorf("-beep-freq must be between 0 and 20000 Hz")
+	}
+	return nil
+}
+
+// beepGraph mixes a sine tone over src that only sounds inside the muted
+// segments, so the censored ranges beep instead of going silent. src must
+// already be a graph label.
+func beepGraph(src string, segments []Segment, freq float64, out string) string {
+	ranges := make([]string, len(segments))
+	for i, seg := range segments {
+		ranges[i] = fmt.Sprintf("between(t,%.3f,%.3f)", seg.Start, seg.End)
+	}
+	return strings.Join([]string{
+		fmt.Sprintf("sine=frequency=%g:sample_rate=48000,volume=0:enable='not(%s)'[beep]", freq, strings.Join(ranges, "+")),
+		fmt.Sprintf("%s[beep]amix=inputs=2:duration=first:normalize=0%s", src, out),
+	}, ";")
+}
@@ -0,0 +1,101 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+var benchmarkPresets = []string{"ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow"}
+
+// benchmarkSizeTolerance is how much larger than the best-compressed sample
+// a preset's output may be and still count as "good enough" when picking the
+// recommendation.
+const benchmarkSizeTolerance = 1.05
+
+type benchmarkResult struct {
+	Preset  string
+	Elapsed time.Duration
+	Size    int64
+}
+
+// runBenchmark encodes the same short sample at each preset and prints how
+// long it took and how large the result was, to help pick a preset for this
+// machine.
+func runBenchmark(cfg Config, sampleLen float64) error {
+	if sampleLen <= 0 {
+		return fmt.Errorf("-benchmark-duration must be greater than 0")
+	}
+
+	start := "0"
+	if cfg.StartTime != "" {
+		start = ffmpegTime(cfg.StartTime)
+	}
+
+	tmp, err := os.CreateTemp("", "mutecut-bench-*.mp4")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	logf("Benchmarking %.0fs sample at CRF %d...\n", sampleLen, cfg.CRF)
+
+	var results []benchmarkResult
+	for _, preset := range benchmarkPresets {
+		logf("  %-10s ", preset)
+		args := []string{
+			"-loglevel", "error",
+			"-ss", start, "-t", strconv.FormatFloat(sampleLen, 'f', 3, 64),
+		}
+		args = append(args, inputSourceArgs(cfg)...)
+		args = append(args,
+			"-an",
+			"-c:v", "libx264", "-preset", preset, "-crf", strconv.Itoa(cfg.CRF),
+			"-y", tmp.Name(),
+		)
+
+		began := time.Now()
+		if err := runFFmpeg(cfg, args); err != nil {
+			return err
+		}
+		elapsed := time.Since(began)
+
+		info, err := os.Stat(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("reading sample: %w", err)
+		}
+		results = append(results, benchmarkResult{Preset: preset, Elapsed: elapsed, Size: info.Size()})
+		logln("done")
+	}
+
+	fmt.Println()
+	fmt.Printf("%-10s %10s %10s %12s\n", "Preset", "Time", "Size (MB)", "Bitrate")
+	for _, r := range results {
+		kbps := float64(r.Size) * 8 / sampleLen / 1000
+		fmt.Printf("%-10s %10s %10.2f %9.0f kb/s\n", r.Preset, r.Elapsed.Round(time.Millisecond), float64(r.Size)/(1024*1024), kbps)
+	}
+
+	fmt.Printf("\nRecommended: -preset %s\n", recommendPreset(results))
+	return nil
+}
+
+// recommendPreset picks the fastest preset whose output is within
+// benchmarkSizeTolerance of the smallest one measured.
+func recommendPreset(results []benchmarkResult) string {
+	smallest := results[0].Size
+	for _, r := range results {
+		if r.Size < smallest {
+			smallest = r.Size
+		}
+	}
+
+	best := results[len(results)-1]
+	for _, r := range results {
+		if float64(r.Size) <= float64(smallest)*benchmarkSizeTolerance && r.Elapsed < best.Elapsed {
+			best = r
+		}
+	}
+	return best.Preset
+}
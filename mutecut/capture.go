@@ -0,0 +1,57 @@
+package mutecut
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Capture inputs are given as "camera:<device>" or "mic:<device>", using the
+// IDs or names shown by -list-devices.
+const (
+	cameraPrefix = "camera:"
+	micPrefix    = "mic:"
+)
+
+func isCaptureInput(input string) bool {
+	return strings.HasPrefix(input, cameraPrefix) || strings.HasPrefix(input, micPrefix)
+}
+
+// isVirtualInput reports inputs that aren't files on disk.
+func isVirtualInput(input string) bool {
+	return isTestSource(input) || isCaptureInput(input)
+}
+
+// captureInputArgs maps a capture input to this platform's ffmpeg device
+// demuxer and device syntax.
+func captureInputArgs(input string) []string {
+	device, isCamera := strings.CutPrefix(input, cameraPrefix)
+	if !isCamera {
+		device = strings.TrimPrefix(input, micPrefix)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		// AVFoundation takes "video:audio"; most cameras only do 30fps
+		if isCamera {
+			return []string{"-f", "avfoundation", "-framerate", "30", "-i", device + ":none"}
+		}
+		return []string{"-f", "avfoundation", "-i", "none:" + device}
+	case "windows":
+		if isCamera {
+			return []string{"-f", "dshow", "-i", "video=" + device}
+		}
+		return []string{"-f", "dshow", "-i", "audio=" + device}
+	default:
+		if isCamera {
+			return []string{"-f", "v4l2", "-i", device}
+		}
+		return []string{"-f", "alsa", "-i", device}
+	}
+}
+
+// captureOutputBase names recordings after the time they were made.
+func captureOutputBase() string {
+	return fmt.Sprintf("capture_%s", time.Now().Format("20060102-150405"))
+}
@@ -0,0 +1,72 @@
+package mutecut
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ffmetadataEscaper escapes the characters ffmpeg's metadata format treats
+// specially in values.
+var ffmetadataEscaper = strings.NewReplacer(
+	`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n",
+)
+
+// exportChapters writes the input's chapters to path in ffmpeg's metadata
+// format (millisecond timebase), ready to be edited and fed back through
+// -import-chapters.
+func exportChapters(cfg Config, path string) error {
+	chapters, err := probeChapters(cfg)
+	if err != nil {
+		return err
+	}
+	if len(chapters) == 0 {
+		return fmt.Errorf("'%s' has no chapters", cfg.InputFile)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, ";FFMETADATA1")
+	for _, c := range chapters {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "[CHAPTER]")
+		fmt.Fprintln(w, "TIMEBASE=1/1000")
+		fmt.Fprintf(w, "START=%d\n", int64(c.Start*1000+0.5))
+		fmt.Fprintf(w, "END=%d\n", int64(c.End*1000+0.5))
+		fmt.Fprintf(w, "title=%s\n", ffmetadataEscaper.Replace(c.Title))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// validateChapterSplit rejects -split-by-chapter without -mp3, which would
+// otherwise just write one whole video.
+func validateChapterSplit(cfg Config) error {
+	if cfg.SplitByChapter && !cfg.ExtractMP3 {
+		return fmt.Errorf("-split-by-chapter only works with -mp3")
+	}
+	return nil
+}
+
+// validateChapterFile checks that path looks like an ffmetadata file so a
+// typo fails here rather than halfway through an encode.
+func validateChapterFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff")) != ";FFMETADATA1" {
+		return fmt.Errorf("'%s' is not an ffmetadata file (first line must be ;FFMETADATA1)", path)
+	}
+	return scanner.Err()
+}
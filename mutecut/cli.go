@@ -0,0 +1,1960 @@
+package mutecut
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type Config struct {
+	InputFile  string
+	OutputFile string
+
+	MaxVideoLen float64
+	MaxFileSize int64
+	Preset      string
+	CRF         int
+	BitDepth    int
+	// Encoder picked by -quality (empty = libx264 defaults)
+	VideoCodec string
+	Format     string // -format container; empty keeps the output's extension
+	AudioCodec string // -acodec for video outputs; empty means AAC
+	HWEncoder  string // Hardware H.264 encoder picked by -hwaccel; empty for software
+	// Bitrate (kb/s) and pass for size-capped two-pass encodes
+	VideoBitrate int
+	Pass         int
+	PassLog      string
+	// Source colour metadata carried into 10-bit output
+	HDR hdrMetadata
+	// Mute Flags
+	MuteStart string
+	MuteEnd   string
+	// Extra ranges to mute (e.g. from subtitle matches)
+	MuteSegments []Segment
+	// Seconds to fade out/in around each muted range (0 = hard cut)
+	MuteFade float64
+	// Play a tone over muted ranges instead of leaving them silent
+	Beep     bool
+	BeepFreq float64
+	// Subtitles to add, muxed as a stream ("soft") or drawn on ("burn")
+	Subs     string
+	SubsMode string
+	// Image overlaid in a corner (or the centre) of the picture
+	Watermark        string
+	WatermarkPos     string
+	WatermarkMargin  int
+	WatermarkOpacity float64
+	// Volume adjustment over a range (mute is the level 0 case)
+	VolStart string
+	VolEnd   string
+	VolLevel float64
+	// Square output (crop or pad to 1:1)
+	SquareSize  int
+	SquareMode  string
+	SquareColor string
+	// Replace Flags (overlay a clip, image or colour over a range)
+	ReplaceStart string
+	ReplaceEnd   string
+	ReplaceWith  string
+
+	StartTime string
+	EndTime   string
+	Duration  string
+	SkipIntro string
+	SkipOutro string
+	// Audio track selection
+	AudioLang string
+	AudioMap  string
+	// Mix several audio streams into one (indices among the audio streams)
+	MixTracks  []int
+	MixWeights string
+	// Volume automation: fixed ranges, and/or a voice track that ducks the audio
+	DuckRanges []DuckRange
+	DuckVoice  string
+	// Gain in dB applied by -peak-normalize
+	PeakGain float64
+	// EBU R128 loudness normalization to an integrated target in LUFS
+	Normalize      bool
+	LoudnessTarget float64
+	// A/V sync correction in seconds (positive delays the audio)
+	AudioDelay float64
+
+	FfmpegBin  string
+	FfprobeBin string
+	Verbose    bool
+	Explain    bool
+	DryRun     bool
+	JSON       bool // Machine-readable results
+	NoAtomic   bool
+	ExtractMP3 bool
+	StreamCopy bool
+	// Files joined by -concat, in order
+	ConcatFiles []string
+
+	// Codec/container for extracted audio, a key of audioFormats, and an
+	// optional VBR level or bitrate overriding its default quality
+	AudioFormat  string
+	AudioQuality string
+
+	// Title slate shown before the clip
+	IntroSlate     string
+	SlateImage     string
+	SlateDuration  float64
+	SlateColor     string
+	SlateTextColor string
+	// Named output size (e.g. 1080p) and whether it may enlarge the input
+	Resolution   string
+	AllowUpscale bool
+	// Playback speed factor (1 = unchanged)
+	Speed float64
+	// Seconds of fade from/to black at the start and end of the output
+	FadeIn  float64
+	FadeOut float64
+	// Scale filter built from -scale, and a w:h:x:y region cropped before it
+	ScaleFilter string
+	Crop        string
+	// Section cut out of the middle, optionally cross-faded over
+	RemoveStart string
+	RemoveEnd   string
+	CutXfade    float64
+	// ffmetadata file whose chapters replace the input's
+	ImportChapters string
+	// Minimum time between progress redraws
+	StatsInterval time.Duration
+	// Longest a single ffprobe call may take (0 = no limit)
+	ProbeTimeout time.Duration
+	// Longest the whole job may take (0 = no limit)
+	Timeout time.Duration
+	// SHA-256 every output, optionally into <output>.sha256 as well
+	Hash        bool
+	HashSidecar bool
+	// Replace existing outputs instead of writing "_N" copies beside them
+	Overwrite bool
+	// Cancelling it kills any running ffmpeg/ffprobe; nil means never
+	Ctx context.Context
+
+	Repair         bool
+	RepairReencode bool
+
+	StrictTime bool
+	// Write one MP3 per chapter instead of a single file
+	SplitByChapter bool
+	// Write one file per stretch of sound between silent gaps
+	SplitSilence bool
+	SilenceMin   float64
+	SilenceNoise float64
+	// Mute (or with TrimSilence, cut) silent stretches found by silencedetect
+	AutoMute    bool
+	TrimSilence bool
+	SilenceCuts []Segment // Input timeline; filled in by applyAutoMute
+	// Animated WebP export
+	ExportWebP  bool
+	WebPFPS     int
+	WebPWidth   int
+	WebPQuality int
+	WebPLoop    int
+	// Animated GIF export (also used for -also-gif)
+	ExportGIF bool
+	GifFPS    int
+	GifWidth  int
+	// Additional outputs rendered alongside the main video
+	AlsoMP3 bool
+	AlsoGIF bool
+	// Drop all container/stream metadata from the output
+	StripMetadata bool
+	// Audio Filters
+	EnhanceSpeech bool
+	// Length of the generated clip when the input is testsrc/sine
+	TestDuration float64
+	// Copy the input to local temp storage before processing
+	LocalizeInput bool
+	// Preflight free-space check on the output volume
+	CheckDiskSpace bool
+
+	Serve     bool
+	ServePort int
+
+	// Progress reporting: expected length of each ffmpeg run's output, and
+	// which stage of a multi-pass job the current run is
+	ExpectedDuration float64
+	Stage            int
+	Stages           int
+	JobStart         time.Time
+
+	// Set once the zero options have had their defaults applied, so a zero
+	// from the CLI or DefaultConfig is kept (see applyDefaults)
+	defaulted bool
+}
+
+type Segment struct {
+	Start float64
+	End   float64
+}
+
+// Main runs the command-line tool: it parses the flags, validates them into
+// a Config and hands the job to Process. It exits the process when done.
+func Main() {
+	os.Exit(run())
+}
+
+// run is Main minus the exit, so deferred cleanup (temp files, the local
+// input copy, the instance lock) happens however the run ends. Errors are
+// reported where they occur; it returns the exit code.
+func run() int {
+	// Ctrl+C or a SIGTERM kills whatever ffmpeg, ffprobe or download is
+	// running and removes its partial output instead of leaving both behind.
+	// Once that's under way a second Ctrl+C exits outright.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	defaults := DefaultConfig()
+	inputPtr := flag.String("i", "", "Input video file (required), or 'testsrc'/'sine' for a generated test input")
+	testDurationPtr := flag.Float64("test-duration", defaults.TestDuration, "Length in seconds of the 'testsrc'/'sine' test input")
+	outputPtr := flag.String("o", "", "Output file (default: auto-generated)")
+	outputDirPtr := flag.String("output-dir", "", "Directory for auto-named outputs (default: next to the input)")
+	jobsPtr := flag.Int("jobs", 1, "With -batch, how many files to encode at once")
+	concatPtr := flag.String("concat", "", "Join these comma-separated files into one output (stream copy when they match)")
+	recursivePtr := flag.Bool("recursive", false, "With -batch, also process subdirectories, mirroring them under -output-dir")
+	batchPtr := flag.String("batch", "", "Process every video in this directory (or matching this glob) with the same settings")
+	slugifyPtr := flag.Bool("slugify", false, "Use a lowercase, hyphenated, ASCII-only auto-generated output name")
+
+	startPtr := flag.String("start", "", "Start time (e.g., '10', '00:01:30.250', '1m30s')")
+	endPtr := flag.String("end", "", "End time (e.g., '20', '00:02:00')")
+	startFramePtr := flag.Int("start-frame", -1, "Start at this frame number (0-based) instead of -start")
+	endFramePtr := flag.Int("end-frame", -1, "End after this frame number (inclusive) instead of -end")
+	maxSizePtr := flag.String("max-size", "", "Two-pass encode to stay under this file size (e.g. '25MB', '700M')")
+	maxLenPtr := flag.String("max-len", "", "Refuse to produce an output longer than this (e.g., '600', '00:10:00')")
+	durationPtr := flag.String("duration", "", "Length to keep/record instead of -end (e.g., '30', '00:01:00')")
+	removeStartPtr := flag.String("remove-start", "", "Start of a section to cut out of the middle")
+	removeEndPtr := flag.String("remove-end", "", "End of the section to cut out")
+	cutXfadePtr := flag.Float64("cut-xfade", 0, "Seconds of cross-dissolve over the -remove-start/-remove-end join (0 = hard cut)")
+	skipIntroPtr := flag.String("skip-intro", "", "Drop this much from the start (e.g., '90', '00:01:30')")
+	clampTimesPtr := flag.Bool("clamp-times", false, "Pull -start/-end inside the input's duration instead of failing")
+	skipOutroPtr := flag.String("skip-outro", "", "Drop this much from the end (e.g., '45', '00:00:45')")
+
+	// Mute Flags
+	muteStartPtr := flag.String("mute-start", "", "Start time to mute (e.g., '00:06:00')")
+	muteEndPtr := flag.String("mute-end", "", "End time to mute (e.g., '00:06:30')")
+	volStartPtr := flag.String("vol-start", "", "Start of a range to change the volume of")
+	volEndPtr := flag.String("vol-end", "", "End of the -vol-start range")
+	volLevelPtr := flag.Float64("vol-level", defaults.VolLevel, "Volume during -vol-start/-vol-end (1 = unchanged, 0 = mute, max 4)")
+	muteFadePtr := flag.Float64("mute-fade", 0, "Seconds to fade out before and back in after each muted range (0 = hard cut)")
+	beepPtr := flag.Bool("beep", false, "Play a censor beep over muted ranges instead of silence")
+	beepFreqPtr := flag.Float64("beep-freq", defaults.BeepFreq, "Frequency of the -beep tone in Hz")
+	muteSubsPtr := flag.String("mute-subs", "", "SRT subtitle file used by -mute-subtitle-regex")
+	muteSubRegexPtr := flag.String("mute-subtitle-regex", "", "Mute every subtitle cue whose text matches this regex")
+	mutePaddingPtr := flag.Float64("mute-padding", 0, "Seconds to widen each subtitle mute by on both sides")
+	subOffsetPtr := flag.Int("subtitle-offset", 0, "Shift subtitle timings by this many milliseconds (negative = earlier)")
+	subsPtr := flag.String("subs", "", "SRT subtitle file to add to the output")
+	subsModePtr := flag.String("subs-mode", defaults.SubsMode, "How to add -subs: soft (a subtitle stream) or burn (drawn into the picture)")
+	vcodecPtr := flag.String("vcodec", "", "Video encoder to use instead of libx264 (e.g. libx265, libvpx-vp9, copy)")
+	acodecPtr := flag.String("acodec", "", "Audio encoder to use instead of aac (e.g. libopus, copy)")
+	formatPtr := flag.String("format", "", "Output container: mp4, mkv, webm (VP9/Opus) or mov; sets the extension")
+	watermarkPtr := flag.String("watermark", "", "Image (e.g. a PNG logo) to overlay on the video")
+	watermarkPosPtr := flag.String("watermark-pos", defaults.WatermarkPos, "Where to put -watermark: tl, tr, bl, br or center")
+	watermarkMarginPtr := flag.Int("watermark-margin", defaults.WatermarkMargin, "Gap in pixels between -watermark and the edges")
+	watermarkOpacityPtr := flag.Float64("watermark-opacity", defaults.WatermarkOpacity, "Opacity of -watermark, from 0 to 1")
+
+	// Slate Flags
+	introSlatePtr := flag.String("intro-slate", "", "Title text for a slate shown before the clip")
+	slateImagePtr := flag.String("slate-image", "", "Background image for the intro slate (instead of a solid colour)")
+	slateDurationPtr := flag.Float64("slate-duration", defaults.SlateDuration, "Seconds the intro slate is shown")
+	slateColorPtr := flag.String("slate-color", defaults.SlateColor, "Background colour of the intro slate")
+	slateTextColorPtr := flag.String("slate-text-color", defaults.SlateTextColor, "Title colour on the intro slate")
+
+	resolutionPtr := flag.String("resolution", "", "Fit the output inside a named size: 480p, 720p, 1080p, 1440p, 4k, vertical-720, vertical-1080")
+	allowUpscalePtr := flag.Bool("allow-upscale", false, "Let -resolution enlarge inputs smaller than the preset")
+
+	// Square Flags
+	squarePtr := flag.Int("square", 0, "Make a square (1:1) output of this size in pixels, e.g. 1080")
+	squareModePtr := flag.String("square-mode", defaults.SquareMode, "How to reach 1:1: 'crop' (center crop) or 'pad'")
+	squareColorPtr := flag.String("square-color", defaults.SquareColor, "Padding colour for -square-mode pad")
+
+	// Replace Flags
+	replaceStartPtr := flag.String("replace-start", "", "Start time of a range to cover up")
+	replaceEndPtr := flag.String("replace-end", "", "End time of a range to cover up")
+	replaceWithPtr := flag.String("replace-with", "", "Image, video clip or colour (e.g., 'black') shown over the replaced range")
+
+	stripMetadataPtr := flag.Bool("strip-metadata", false, "Remove all metadata (creation time, device, GPS, encoder) from the output")
+	peakNormalizePtr := flag.Bool("peak-normalize", false, "Raise/lower the audio so its loudest peak hits -peak-ceiling")
+	peakCeilingPtr := flag.Float64("peak-ceiling", 0, "Target peak for -peak-normalize, in dBFS")
+	normalizePtr := flag.Bool("normalize", false, "Even out loudness with ffmpeg's loudnorm (EBU R128)")
+	loudnessTargetPtr := flag.Float64("loudness-target", defaults.LoudnessTarget, "Integrated loudness target for -normalize, in LUFS")
+	enhanceSpeechPtr := flag.Bool("enhance-speech", false, "Make dialogue louder and clearer (high-pass + compressor)")
+
+	presetPtr := flag.String("preset", defaults.Preset, "Encoding preset")
+	fadeInPtr := flag.Float64("fade-in", 0, "Fade the picture in from black over this many seconds")
+	fadeOutPtr := flag.Float64("fade-out", 0, "Fade the picture out to black over the last this many seconds")
+	speedPtr := flag.Float64("speed", defaults.Speed, "Playback speed, e.g. 2 for timelapse or 0.5 for slow motion")
+	cropPtr := flag.String("crop", "", "Crop the picture to w:h:x:y before any scaling")
+	scalePtr := flag.String("scale", "", "Scale the output: WxH, one side (1280x, x720, 720p) or a factor like 0.5")
+	hwaccelPtr := flag.String("hwaccel", "none", "Hardware H.264 encoder: nvenc, qsv, vaapi or none")
+	qualityPtr := flag.String("quality", "", "Encoder bundle: fast, balanced, small (HEVC) or archive; -preset/-crf still override")
+	bitDepthPtr := flag.Int("bitdepth", defaults.BitDepth, "Output bit depth: 8 (H.264) or 10 (HEVC, keeps HDR colour metadata)")
+	crfPtr := flag.Int("crf", defaults.CRF, "CRF Quality")
+	autoCRFPtr := flag.Bool("auto-crf", false, "Pick the CRF from a quick complexity analysis of the input")
+	verbosePtr := flag.Bool("v", false, "Verbose output")
+	quietPtr := flag.Bool("quiet", false, "Only print errors and warnings (to stderr)")
+	logTimePtr := flag.Bool("log-time", false, "Prefix each log line with the time")
+	timeoutPtr := flag.Duration("timeout", 0, "Stop the job if it takes longer than this (e.g. '2h', 0 = never)")
+	probeTimeoutPtr := flag.Duration("probe-timeout", defaults.ProbeTimeout, "Give up on ffprobe after this long (0 = never)")
+	statsIntervalPtr := flag.Duration("stats-interval", defaults.StatsInterval, "How often to refresh progress (e.g. '500ms', '10s')")
+	overwritePtr := flag.Bool("overwrite", false, "Replace existing output files instead of writing name_1.ext etc.")
+	noAtomicPtr := flag.Bool("no-atomic", false, "Write directly to the output instead of a temp file renamed on success")
+	explainPtr := flag.Bool("explain", false, "Print each ffmpeg command with every argument explained")
+	dryRunPtr := flag.Bool("dry-run", false, "Print the ffmpeg commands that would run without running them")
+	setupPtr := flag.Bool("setup", false, "Download static ffmpeg/ffprobe builds into ./bin, then exit")
+	listDevicesPtr := flag.Bool("list-devices", false, "List cameras and microphones ffmpeg can capture from, then exit")
+	keyframesPtr := flag.Bool("keyframes", false, "List keyframe timestamps of the input and exit")
+	exportChaptersPtr := flag.String("export-chapters", "", "Write the input's chapters to this file (ffmetadata format) and exit")
+	importChaptersPtr := flag.String("import-chapters", "", "Replace the output's chapters with this ffmetadata file")
+	jsonPtr := flag.Bool("json", false, "Machine-readable JSON output")
+	thumbnailsPtr := flag.Int("thumbnails", 0, "Save this many evenly spaced JPEG frames into <input>_thumbs and exit")
+	thumbIntervalPtr := flag.Float64("thumb-interval", 0, "Save a thumbnail every this many seconds instead of a fixed count")
+	thumbWidthPtr := flag.Int("thumb-width", 320, "Thumbnail width in pixels (0 = original size)")
+	infoPtr := flag.Bool("info", false, "Print a summary of the input's format and streams and exit")
+	infoJSONPtr := flag.Bool("info-json", false, "Print ffprobe's raw JSON for the input and exit")
+	singleInstancePtr := flag.Bool("single-instance", false, "Refuse to run while another instance is processing")
+	singleWaitPtr := flag.Bool("single-instance-wait", false, "Like -single-instance, but wait for the other run to finish")
+	benchmarkPtr := flag.Bool("benchmark", false, "Encode a short sample at several presets and compare speed/size")
+	benchmarkLenPtr := flag.Float64("benchmark-duration", 10, "Sample length in seconds for -benchmark")
+	strictTimePtr := flag.Bool("strict-time", false, "Only accept SS, MM:SS or HH:MM:SS time values")
+	mp3Ptr := flag.Bool("mp3", false, "Extract MP3 audio")
+	audioFormatPtr := flag.String("audio-format", defaults.AudioFormat, "Format for extracted audio: mp3, aac, flac, wav or opus (implies audio extraction)")
+	audioQualityPtr := flag.String("audio-quality", "", "Extracted audio quality: VBR level 0-9 (mp3) or bitrate like 128k")
+	copyPtr := flag.Bool("copy", false, "Trim without re-encoding (cuts snap to keyframes; no filters)")
+	webpPtr := flag.Bool("webp", false, "Export the segment as an animated WebP")
+	repairPtr := flag.Bool("repair", false, "Remux a broken/truncated file into a fresh container without re-encoding")
+	repairReencodePtr := flag.Bool("repair-reencode", false, "With -repair, fall back to a full re-encode if remuxing fails")
+	webpFPSPtr := flag.Int("webp-fps", defaults.WebPFPS, "Frame rate for -webp")
+	webpWidthPtr := flag.Int("webp-width", defaults.WebPWidth, "Width in pixels for -webp (height keeps aspect)")
+	webpQualityPtr := flag.Int("webp-quality", defaults.WebPQuality, "Quality for -webp (0-100)")
+	webpLoopPtr := flag.Int("webp-loop", 0, "Loop count for -webp (0 = forever)")
+	gifPtr := flag.Bool("gif", false, "Export the segment as an animated GIF")
+	gifFPSPtr := flag.Int("gif-fps", defaults.GifFPS, "Frame rate for -gif/-also-gif")
+	gifWidthPtr := flag.Int("gif-width", defaults.GifWidth, "Width in pixels for -gif/-also-gif (height follows)")
+	splitSilencePtr := flag.Bool("split-silence", false, "Split into numbered files at silent gaps")
+	silenceMinPtr := flag.Float64("silence-min", defaults.SilenceMin, "Shortest gap, in seconds, that -split-silence splits at")
+	silenceNoisePtr := flag.Float64("silence-noise", defaults.SilenceNoise, "Level, in dB, below which audio counts as silence")
+	silenceThresholdPtr := flag.String("silence-threshold", "", "Same as -silence-noise, written like '-30dB'")
+	autoMutePtr := flag.Bool("auto-mute", false, "Mute every silent stretch (see -silence-min, -silence-threshold)")
+	trimSilencePtr := flag.Bool("trim-silence", false, "Cut silent stretches out instead of muting them (implies -auto-mute)")
+	splitChapterPtr := flag.Bool("split-by-chapter", false, "With -mp3, write one MP3 per chapter")
+	alsoMP3Ptr := flag.Bool("also-mp3", false, "Also write an MP3 of the processed video")
+	alsoGIFPtr := flag.Bool("also-gif", false, "Also write a GIF of the processed video")
+	urlPtr := flag.String("url", "", "YouTube Video URL")
+	localizePtr := flag.Bool("localize-input", false, "Copy the input to a local temp file before processing (for network shares)")
+	checkDiskPtr := flag.Bool("check-disk-space", false, "Abort early if the output volume looks too small")
+	downloadOnlyPtr := flag.Bool("download-only", false, "Download the YouTube video and exit without processing")
+	ytQualityPtr := flag.String("yt-quality", "best", "YouTube download quality: best, worst or a height like 720p")
+	ytMaxDurationPtr := flag.String("yt-max-duration", "", "Refuse to download YouTube videos longer than this (e.g., '2:00:00'; 0 = no cap)")
+	hashPtr := flag.Bool("hash", false, "Print the SHA-256 of each output file")
+	hashSidecarPtr := flag.Bool("hash-sidecar", false, "Also write each hash to <output>.sha256 (implies -hash)")
+	servePtr := flag.Bool("serve", false, "Serve the output over HTTP after processing")
+	servePortPtr := flag.Int("serve-port", defaults.ServePort, "Port for -serve")
+	audioDelayPtr := flag.String("audio-delay", "", "Shift audio by this many seconds (negative = earlier); overrides -auto-sync")
+	autoSyncPtr := flag.Bool("auto-sync", false, "Detect and correct a constant A/V offset")
+	mixAudioPtr := flag.Bool("mix-audio", false, "Mix the audio tracks into a single track")
+	mixTracksPtr := flag.String("mix-tracks", "", "Audio tracks to mix, e.g. '0,2' (default: all)")
+	mixWeightsPtr := flag.String("mix-weights", "", "Per-track mix weights, e.g. '1,0.4'")
+	duckingFilePtr := flag.String("ducking-file", "", "File of 'start end level' ranges to lower the volume to")
+	duckVoicePtr := flag.String("ducking-voice", "", "Voice track to mix in; the original audio ducks under it (sidechain)")
+	audioLangPtr := flag.String("audio-lang-select", "", "Keep only the audio track with this language tag (e.g., 'eng')")
+	configPtr := flag.String("config", "", "JSON file of default option values; flags on the command line override it")
+
+	flag.Parse()
+
+	// Captured before the config file and environment fill in defaults, so
+	// only what was typed counts as explicit from here on
+	explicit := explicitFlags()
+	if *configPtr != "" {
+		if err := applyConfigFile(*configPtr, explicit); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if err := applyEnvDefaults(explicit); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	switch {
+	case *quietPtr && *verbosePtr:
+		errorln("Error: use either -quiet or -v, not both.")
+		return 1
+	case *quietPtr:
+		minLevel = levelWarn
+	case *verbosePtr:
+		minLevel = levelDebug
+	}
+	logTimestamps = *logTimePtr
+
+	if *setupPtr {
+		if err := setupFFmpeg("bin"); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *listDevicesPtr {
+		ffmpegBin := resolveBinary("ffmpeg")
+		if ffmpegBin == "" {
+			errorln("Error: ffmpeg not found in 'bin' folder or system PATH.")
+			return 1
+		}
+		printCaptureDevices(Config{FfmpegBin: ffmpegBin, Ctx: ctx})
+		return 0
+	}
+
+	if *batchPtr != "" {
+		if *servePtr {
+			errorln("Error: -serve can't be used with -batch; it would stop after the first file.")
+			return 1
+		}
+		if *singleInstancePtr || *singleWaitPtr {
+			lock, err := acquireInstanceLock(*singleWaitPtr)
+			if err != nil {
+				errorf("Error: %v\n", err)
+				return 1
+			}
+			defer lock.Close()
+		}
+		return runBatch(ctx, batchOptions{
+			Target:    *batchPtr,
+			OutputDir: *outputDirPtr,
+			Jobs:      *jobsPtr,
+			Recursive: *recursivePtr,
+			Hash:      *hashPtr || *hashSidecarPtr,
+		})
+	}
+
+	var concatList []string
+	if *concatPtr != "" {
+		files, err := parseConcatList(*concatPtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		if *inputPtr != "" || *urlPtr != "" {
+			errorln("Error: -concat takes the place of -i/-url; list every file in -concat.")
+			return 1
+		}
+		if *startPtr != "" || *endPtr != "" || *muteStartPtr != "" || *removeStartPtr != "" {
+			errorln("Error: -concat joins whole files; trim or mute the result in a second run.")
+			return 1
+		}
+		concatList = files
+		*inputPtr = files[0] // Names the output and drives the usual input checks
+	}
+
+	// Check if any flags were provided (excluding default values where possible to detect)
+	// A simple way is to check if input is empty, as it's required for non-interactive mode.
+	interactive := false
+	if *inputPtr == "" && *urlPtr == "" {
+		interactive = true
+		// Try interactive mode
+		logln("No input file provided via flags. Entering Interactive Mode...")
+		interactiveConfig := interactiveMode()
+
+		// Merge interactive config into the main logic
+		// We'll just overwrite the pointers or variables used later
+		if interactiveConfig.InputFile != "" {
+			*inputPtr = interactiveConfig.InputFile
+		}
+		// If interactive mode returned a URL (we'll handle this by checking if InputFile is a URL or adding a field)
+		// Actually, let's just use InputFile for both and detect if it's a URL.
+
+		*outputPtr = interactiveConfig.OutputFile // might be empty, auto-gen logic handles it
+		*startPtr = interactiveConfig.StartTime
+		*endPtr = interactiveConfig.EndTime
+
+		*muteStartPtr = interactiveConfig.MuteStart
+		*muteEndPtr = interactiveConfig.MuteEnd
+		*mp3Ptr = interactiveConfig.ExtractMP3
+		// We keep defaults for others or could ask for them too, but let's stick to the requested ones
+	}
+
+	if *inputPtr == "" && *urlPtr == "" {
+		errorln("Error: Input file or YouTube URL required.")
+		return 1
+	}
+
+	var ytMaxDuration, maxLen float64
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *float64
+	}{
+		{"-yt-max-duration", *ytMaxDurationPtr, &ytMaxDuration},
+		{"-max-len", *maxLenPtr, &maxLen},
+	} {
+		if f.value == "" {
+			continue
+		}
+		seconds, err := ParseTimeToSeconds(f.value)
+		if err != nil {
+			errorf("Error: %s: %v\n", f.name, err)
+			return 1
+		}
+		*f.dst = seconds
+	}
+
+	// Handle YouTube Download
+	ytOpts := downloadOptions{
+		CheckSpace:  *checkDiskPtr,
+		MaxDuration: ytMaxDuration,
+		Quality:     *ytQualityPtr,
+		Verbose:     *verbosePtr,
+		Ctx:         ctx,
+	}
+	ytURL := *urlPtr
+	if ytURL == "" {
+		ytURL = *inputPtr
+	}
+	if isPlaylistURL(ytURL) {
+		// Playlists are only downloaded; run -batch on the folder to process them
+		if err := downloadYoutubePlaylist(ytURL, ytOpts); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	// Only audio is kept, so skip downloading the video; the audio file is
+	// a temporary input removed once converted
+	ytOpts.AudioOnly = (*mp3Ptr || explicit["audio-format"]) && !*downloadOnlyPtr
+	downloaded := false
+	var tempFiles []string // Removed once the job is done
+	defer func() {
+		for _, f := range tempFiles {
+			os.Remove(f)
+		}
+	}()
+	if *urlPtr != "" {
+		logln("YouTube URL provided. Downloading...")
+		downloadedFile, err := downloadYoutubeVideo(*urlPtr, ytOpts)
+		if err != nil {
+			errorf("Error downloading YouTube video: %v\n", err)
+			return 1
+		}
+		*inputPtr = downloadedFile
+		downloaded = true
+		if ytOpts.AudioOnly {
+			tempFiles = append(tempFiles, downloadedFile)
+		}
+	} else if strings.HasPrefix(*inputPtr, "http://") || strings.HasPrefix(*inputPtr, "https://") || strings.HasPrefix(*inputPtr, "www.") {
+		// Detect URL from interactive input
+		logln("YouTube URL detected. Downloading...")
+		downloadedFile, err := downloadYoutubeVideo(*inputPtr, ytOpts)
+		if err != nil {
+			errorf("Error downloading YouTube video: %v\n", err)
+			return 1
+		}
+		*inputPtr = downloadedFile
+		downloaded = true
+		if ytOpts.AudioOnly {
+			tempFiles = append(tempFiles, downloadedFile)
+		}
+	}
+
+	if *downloadOnlyPtr {
+		if !downloaded {
+			errorln("Error: -download-only requires a YouTube URL.")
+			return 1
+		}
+		logf("Saved: %s\n", *inputPtr)
+		return 0
+	}
+
+	// Validate Input File (generated test sources and devices have no file to check)
+	if isTestSource(*inputPtr) {
+		if *testDurationPtr <= 0 {
+			errorln("Error: -test-duration must be greater than 0.")
+			return 1
+		}
+	} else if isCaptureInput(*inputPtr) {
+		if *durationPtr == "" && *endPtr == "" {
+			warnln("Warning: no -duration given, recording until you press 'q'.")
+		}
+	} else {
+		info, err := os.Stat(*inputPtr)
+		if os.IsNotExist(err) {
+			errorf("Error: Input file '%s' does not exist.\n", *inputPtr)
+			return 1
+		}
+		if err != nil {
+			errorf("Error: Cannot access input file: %v\n", err)
+			return 1
+		}
+		if info.IsDir() {
+			errorf("Error: Input '%s' is a directory. Please specify a video file.\n", *inputPtr)
+			return 1
+		}
+	}
+
+	outputFile := *outputPtr
+	if outputFile == "" {
+		ext := filepath.Ext(*inputPtr)
+		base := strings.TrimSuffix(*inputPtr, ext)
+		if isTestSource(*inputPtr) {
+			ext = ".mp4"
+		}
+		suffix := "_cleaned"
+		if isCaptureInput(*inputPtr) {
+			base, ext, suffix = captureOutputBase(), ".mp4", ""
+		}
+
+		if *muteStartPtr != "" {
+			suffix += "_muted"
+		}
+		if *repairPtr {
+			suffix = "_repaired"
+		}
+		if concatList != nil {
+			suffix = "_joined"
+		}
+		if *slugifyPtr {
+			base = filepath.Join(filepath.Dir(base), slugify(filepath.Base(base)+suffix))
+			suffix = ""
+		}
+		outputFile = base + suffix + ext
+		if *outputDirPtr != "" {
+			outputFile = filepath.Join(*outputDirPtr, filepath.Base(outputFile))
+		}
+	}
+
+	cfg := Config{
+		InputFile:  *inputPtr,
+		OutputFile: outputFile,
+		Ctx:        ctx,
+
+		MaxVideoLen: maxLen,
+
+		StartTime:  *startPtr,
+		EndTime:    *endPtr,
+		Duration:   *durationPtr,
+		SkipIntro:  *skipIntroPtr,
+		SkipOutro:  *skipOutroPtr,
+		MuteStart:  *muteStartPtr,
+		MuteEnd:    *muteEndPtr,
+		MuteFade:   *muteFadePtr,
+		Beep:       *beepPtr,
+		BeepFreq:   *beepFreqPtr,
+		Subs:       *subsPtr,
+		SubsMode:   *subsModePtr,
+		VolStart:   *volStartPtr,
+		VolEnd:     *volEndPtr,
+		VolLevel:   *volLevelPtr,
+		Preset:     *presetPtr,
+		CRF:        *crfPtr,
+		BitDepth:   *bitDepthPtr,
+		Verbose:    *verbosePtr,
+		Explain:    *explainPtr,
+		DryRun:     *dryRunPtr,
+		JSON:       *jsonPtr,
+		NoAtomic:   *noAtomicPtr,
+		StrictTime: *strictTimePtr,
+		ExtractMP3: *mp3Ptr || explicit["audio-format"],
+		StreamCopy: *copyPtr,
+		AlsoMP3:    *alsoMP3Ptr,
+		AlsoGIF:    *alsoGIFPtr,
+		AudioLang:  *audioLangPtr,
+		Serve:      *servePtr,
+		ServePort:  *servePortPtr,
+
+		StatsInterval:  *statsIntervalPtr,
+		ProbeTimeout:   *probeTimeoutPtr,
+		Timeout:        *timeoutPtr,
+		Hash:           *hashPtr || *hashSidecarPtr,
+		HashSidecar:    *hashSidecarPtr,
+		Overwrite:      *overwritePtr,
+		ImportChapters: *importChaptersPtr,
+
+		SplitSilence: *splitSilencePtr,
+		SilenceMin:   *silenceMinPtr,
+		SilenceNoise: *silenceNoisePtr,
+
+		AutoMute:    *autoMutePtr || *trimSilencePtr,
+		TrimSilence: *trimSilencePtr,
+
+		Format:           *formatPtr,
+		AudioCodec:       *acodecPtr,
+		Watermark:        *watermarkPtr,
+		WatermarkPos:     *watermarkPosPtr,
+		WatermarkMargin:  *watermarkMarginPtr,
+		WatermarkOpacity: *watermarkOpacityPtr,
+
+		IntroSlate:     *introSlatePtr,
+		SlateImage:     *slateImagePtr,
+		SlateDuration:  *slateDurationPtr,
+		SlateColor:     *slateColorPtr,
+		SlateTextColor: *slateTextColorPtr,
+
+		Resolution:   *resolutionPtr,
+		AllowUpscale: *allowUpscalePtr,
+		Crop:         *cropPtr,
+
+		Speed:   *speedPtr,
+		FadeIn:  *fadeInPtr,
+		FadeOut: *fadeOutPtr,
+
+		RemoveStart: *removeStartPtr,
+		RemoveEnd:   *removeEndPtr,
+		CutXfade:    *cutXfadePtr,
+
+		Repair:         *repairPtr,
+		RepairReencode: *repairReencodePtr,
+
+		SquareSize:  *squarePtr,
+		SquareMode:  *squareModePtr,
+		SquareColor: *squareColorPtr,
+
+		ReplaceStart: *replaceStartPtr,
+		ReplaceEnd:   *replaceEndPtr,
+		ReplaceWith:  *replaceWithPtr,
+
+		TestDuration:   *testDurationPtr,
+		LocalizeInput:  *localizePtr,
+		EnhanceSpeech:  *enhanceSpeechPtr,
+		StripMetadata:  *stripMetadataPtr,
+		CheckDiskSpace: *checkDiskPtr,
+		SplitByChapter: *splitChapterPtr,
+
+		ExportWebP:  *webpPtr,
+		WebPFPS:     *webpFPSPtr,
+		WebPWidth:   *webpWidthPtr,
+		WebPQuality: *webpQualityPtr,
+		WebPLoop:    *webpLoopPtr,
+
+		ExportGIF: *gifPtr,
+		GifFPS:    *gifFPSPtr,
+		GifWidth:  *gifWidthPtr,
+
+		ConcatFiles:  concatList,
+		AudioFormat:  *audioFormatPtr,
+		AudioQuality: *audioQualityPtr,
+
+		Normalize:      *normalizePtr,
+		LoudnessTarget: *loudnessTargetPtr,
+
+		defaulted: true,
+	}
+
+	cfg.FfmpegBin = resolveBinary("ffmpeg")
+	cfg.FfprobeBin = resolveBinary("ffprobe")
+	debugf("Using ffmpeg: %s\n", cfg.FfmpegBin)
+	debugf("Using ffprobe: %s\n", cfg.FfprobeBin)
+
+	if cfg.FfmpegBin == "" || cfg.FfprobeBin == "" {
+		errorln("Error: ffmpeg or ffprobe not found in 'bin' folder or system PATH.")
+		errorln("Run with -setup to download them (or use the setup_ffmpeg script).")
+		return 1
+	}
+
+	if *muteSubRegexPtr != "" {
+		if *muteSubsPtr == "" {
+			errorln("Error: -mute-subtitle-regex requires -mute-subs <file.srt>.")
+			return 1
+		}
+		segments, err := muteSegmentsFromSubtitles(*muteSubsPtr, *muteSubRegexPtr, *mutePaddingPtr, *subOffsetPtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		if len(segments) == 0 {
+			warnln("Warning: no subtitle cues matched, nothing muted.")
+		}
+		cfg.MuteSegments = segments
+	}
+
+	parseTime := ParseTimeToSeconds
+	if cfg.StrictTime {
+		parseTime = parseTimeStrict
+	}
+	if err := validateTimeFlags(cfg, parseTime); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+
+	if err := validateAudioFormat(cfg.AudioFormat); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateChapterSplit(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if cfg.AudioQuality != "" {
+		if _, err := audioQualityArgs(cfg.AudioQuality, cfg.AudioFormat); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if cfg.ExportWebP {
+		if err := validateWebP(cfg); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		cfg.OutputFile = replaceExt(cfg.OutputFile, ".webp")
+	}
+	if cfg.ExportGIF || cfg.AlsoGIF {
+		if err := validateGIF(cfg); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if cfg.ExportGIF {
+		if cfg.ExportWebP {
+			errorln("Error: use either -gif or -webp, not both.")
+			return 1
+		}
+		cfg.OutputFile = replaceExt(cfg.OutputFile, ".gif")
+	}
+	if cfg.Duration != "" && cfg.EndTime != "" {
+		errorln("Error: use either -end or -duration, not both.")
+		return 1
+	}
+	if err := validateSquare(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateReplace(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if *silenceThresholdPtr != "" {
+		if explicit["silence-noise"] {
+			errorln("Error: use either -silence-threshold or -silence-noise, not both.")
+			return 1
+		}
+		db, err := parseSilenceThreshold(*silenceThresholdPtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		cfg.SilenceNoise = db
+	}
+	if (cfg.SplitSilence || cfg.AutoMute) && cfg.SilenceMin <= 0 {
+		errorln("Error: -silence-min must be greater than 0.")
+		return 1
+	}
+	if err := validateAutoMute(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if (cfg.VolStart == "") != (cfg.VolEnd == "") {
+		errorln("Error: -vol-start and -vol-end must be used together.")
+		return 1
+	}
+	if cfg.VolLevel < 0 || cfg.VolLevel > maxVolumeLevel {
+		errorf("Error: -vol-level must be between 0 and %g.\n", maxVolumeLevel)
+		return 1
+	}
+	if err := validateWatermark(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateSubs(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateBeep(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if cfg.MuteFade < 0 {
+		errorln("Error: -mute-fade cannot be negative.")
+		return 1
+	}
+	if err := validateSlate(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateSpeed(cfg.Speed); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	// Before anything probes the input, so every read after this is local
+	if cfg.LocalizeInput && !isVirtualInput(cfg.InputFile) {
+		localPath, cleanup, err := localizeInput(ctx, cfg.InputFile)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		defer cleanup()
+		cfg.InputFile = localPath
+	}
+	if err := validateCrop(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateResolution(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if *scalePtr != "" {
+		if cfg.Resolution != "" {
+			errorln("Error: use either -scale or -resolution, not both.")
+			return 1
+		}
+		filter, err := scaleFilter(*scalePtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		cfg.ScaleFilter = filter
+	}
+	if err := validateRemove(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if *qualityPtr != "" {
+		if err := applyQuality(&cfg, *qualityPtr, explicit); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if cfg.Format != "" {
+		if err := applyFormat(&cfg, explicit); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if *vcodecPtr != "" {
+		cfg.VideoCodec = *vcodecPtr // Overrides -quality and -format
+	}
+	if err := validatePreset(cfg.Preset); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateCRF(cfg.VideoCodec, cfg.CRF); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := validateBitDepth(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if *hwaccelPtr != "none" && (cfg.BitDepth == 10 || (cfg.VideoCodec != "" && cfg.VideoCodec != "libx264")) {
+		errorln("Error: -hwaccel encodes H.264 only; it can't be combined with -bitdepth 10 or a non-H.264 -vcodec, -quality or -format.")
+		return 1
+	}
+	if encoder, err := resolveHWAccel(cfg, *hwaccelPtr); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	} else {
+		cfg.HWEncoder = encoder
+	}
+	if cfg.BitDepth == 10 {
+		hdr, err := probeHDRMetadata(cfg)
+		if err != nil {
+			errorf("Error: cannot read colour metadata: %v\n", err)
+			return 1
+		}
+		if hdr.Transfer != "smpte2084" && hdr.Transfer != "arib-std-b67" {
+			warnln("Warning: input doesn't look like HDR (PQ/HLG); encoding 10-bit SDR.")
+		}
+		cfg.HDR = hdr
+	}
+
+	if err := applySkipIntroOutro(&cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	// Input -ss is frame-accurate here because the video is always re-encoded
+	if err := applyFrameTrim(&cfg, *startFramePtr, *endFramePtr); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := trimRemovedEdge(&cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if !isVirtualInput(cfg.InputFile) && (cfg.StartTime != "" || cfg.EndTime != "" || cfg.MuteStart != "" || cfg.MuteEnd != "") {
+		if duration, err := GetDuration(cfg); err == nil {
+			warnings := checkTimeUnits(cfg, duration)
+			for _, w := range warnings {
+				warnf("Warning: %s\n", w)
+			}
+			if len(warnings) > 0 && interactive && !confirm("Continue with these times?") {
+				return 1
+			}
+		}
+	}
+	if *clampTimesPtr {
+		if err := clampTimes(&cfg); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if *maxSizePtr != "" {
+		size, err := parseSize(*maxSizePtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		cfg.MaxFileSize = size
+	}
+	if err := validateCodecs(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if cfg.AutoMute {
+		if err := applyAutoMute(&cfg); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if cfg.Subs != "" {
+		retimed, err := prepareSubtitles(cfg, *subOffsetPtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		cfg.Subs = retimed
+		tempFiles = append(tempFiles, retimed)
+	}
+	if err := validateFade(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := checkMaxLen(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if err := selectAudioByLanguage(&cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if *duckingFilePtr != "" {
+		ranges, err := parseDuckingFile(*duckingFilePtr)
+		if err != nil {
+			errorf("Error reading ducking file: %v\n", err)
+			return 1
+		}
+		cfg.DuckRanges = ranges
+	}
+	if *duckVoicePtr != "" {
+		if _, err := os.Stat(*duckVoicePtr); err != nil {
+			errorf("Error: cannot access ducking voice track: %v\n", err)
+			return 1
+		}
+		cfg.DuckVoice = *duckVoicePtr
+	}
+	if *mixAudioPtr {
+		if cfg.AudioLang != "" {
+			errorln("Error: -mix-audio cannot be combined with -audio-lang-select.")
+			return 1
+		}
+		if err := resolveMixTracks(&cfg, *mixTracksPtr, *mixWeightsPtr); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if *audioDelayPtr != "" {
+		delay, err := strconv.ParseFloat(*audioDelayPtr, 64)
+		if err != nil {
+			errorf("Error: invalid -audio-delay '%s'\n", *audioDelayPtr)
+			return 1
+		}
+		cfg.AudioDelay = delay
+	} else if *autoSyncPtr {
+		delay, err := detectAudioDelay(cfg)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		logf("Detected A/V offset: applying -audio-delay %.3f (pass -audio-delay to override)\n", delay)
+		cfg.AudioDelay = delay
+	}
+	if err := validateAudioExtract(cfg); err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+
+	if *exportChaptersPtr != "" {
+		if err := exportChapters(cfg, *exportChaptersPtr); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		logf("Chapters written to: %s\n", *exportChaptersPtr)
+		return 0
+	}
+	if cfg.ImportChapters != "" {
+		if err := validateChapterFile(cfg.ImportChapters); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if *keyframesPtr {
+		if err := printKeyframes(cfg, *jsonPtr); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if *thumbnailsPtr > 0 || *thumbIntervalPtr > 0 {
+		dir := thumbnailDir(*inputPtr, *outputDirPtr)
+		if err := writeThumbnails(cfg, dir, *thumbnailsPtr, *thumbIntervalPtr, *thumbWidthPtr); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if *infoPtr || *infoJSONPtr {
+		if err := printInfo(cfg, *infoJSONPtr || *jsonPtr); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *singleInstancePtr || *singleWaitPtr {
+		lock, err := acquireInstanceLock(*singleWaitPtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		defer lock.Close()
+	}
+
+	if *benchmarkPtr {
+		if err := runBenchmark(cfg, *benchmarkLenPtr); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *autoCRFPtr && !cfg.ExtractMP3 && !cfg.Repair {
+		crf, err := chooseAutoCRF(cfg)
+		if err != nil {
+			errorf("Error: auto CRF analysis failed: %v\n", err)
+			return 1
+		}
+		logf("Auto CRF: %d\n", crf)
+		cfg.CRF = crf
+	}
+
+	if cfg.Normalize {
+		if *peakNormalizePtr {
+			errorln("Error: use either -normalize or -peak-normalize, not both.")
+			return 1
+		}
+		if err := validateLoudnessTarget(cfg.LoudnessTarget); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if *peakNormalizePtr {
+		if *peakCeilingPtr > 0 {
+			errorln("Error: -peak-ceiling must be 0 dBFS or below.")
+			return 1
+		}
+		gain, err := measurePeakGain(cfg, *peakCeilingPtr)
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		logf("Peak normalize: %+.2f dB\n", gain)
+		cfg.PeakGain = gain
+	}
+
+	_ = os.MkdirAll(filepath.Dir(cfg.OutputFile), 0755)
+
+	if cfg.CheckDiskSpace && !isVirtualInput(cfg.InputFile) {
+		needed, err := estimateOutputSize(cfg)
+		if err == nil {
+			err = ensureDiskSpace(cfg.OutputFile, needed)
+		}
+		if err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if cfg.StreamCopy {
+		if err := validateStreamCopy(cfg); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+		logln("Note: -copy cuts on keyframes, so the start may land slightly before -start.")
+	}
+
+	result, err := Process(cfg)
+	if err != nil {
+		errorf("Error: %v\n", err)
+		return 1
+	}
+	if cfg.DryRun {
+		return 0 // Nothing was written, so no stats, hashes or serving
+	}
+	cfg.OutputFile = result.Output
+	printStats(cfg, result)
+	if path := os.Getenv(resultFileEnv); path != "" {
+		// Running as one file of a -batch; hand the result back to it
+		if err := writeResultFile(path, cfg, result); err != nil {
+			errorf("Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if cfg.Serve {
+		if err := serveOutput(ctx, cfg.OutputFile, cfg.ServePort); err != nil {
+			errorf("Error serving output: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// replaceExt swaps the extension of path for ext (which includes the dot).
+func replaceExt(path, ext string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+func getInputArgs(cfg Config) []string {
+	args := []string{}
+	if cfg.StartTime != "" {
+		args = append(args, "-ss", ffmpegTime(cfg.StartTime))
+	}
+	if cfg.EndTime != "" {
+		args = append(args, "-to", ffmpegTime(cfg.EndTime))
+	} else if cfg.Duration != "" {
+		args = append(args, "-t", ffmpegTime(cfg.Duration))
+	}
+	args = append(args, inputSourceArgs(cfg)...)
+	return args
+}
+
+// stripMetadataArgs drop global, stream and chapter metadata, and keep the
+// muxer/encoders from stamping their own version tags into the file.
+var stripMetadataArgs = []string{
+	"-map_metadata", "-1",
+	"-map_chapters", "-1",
+	"-fflags", "+bitexact",
+	"-flags:v", "+bitexact",
+	"-flags:a", "+bitexact",
+}
+
+// speechEnhanceFilters cut low-frequency rumble, even out the level of the
+// dialogue, then add back some gain lost to compression.
+var speechEnhanceFilters = []string{
+	"highpass=f=80",
+	"acompressor=threshold=-21dB:ratio=4:attack=5:release=150:makeup=2",
+}
+
+func simpleCut(cfg Config) error {
+	// Catch bad times here too, for callers that fill them in themselves
+	if err := validateTimeFlags(cfg, ParseTimeToSeconds); err != nil {
+		return err
+	}
+	if cfg.StreamCopy {
+		return runFFmpeg(cfg, copyCutArgs(cfg))
+	}
+	if cfg.MaxFileSize > 0 {
+		return twoPassCut(cfg)
+	}
+	return runFFmpeg(cfg, simpleCutArgs(cfg))
+}
+
+// audioFilters is the -af chain for the peak gain, ducking ranges, speech
+// cleanup, loudness and the volume and mute ranges, along with the mute
+// ranges on the output's timeline.
+func audioFilters(cfg Config) ([]string, []Segment) {
+	var filters []string
+	if cfg.PeakGain != 0 {
+		filters = append(filters, peakGainFilter(cfg.PeakGain))
+	}
+	if duck := windowDuckRanges(cfg, cfg.DuckRanges); len(duck) > 0 {
+		// Like the mute ranges, these are timed against the untrimmed input
+		filters = append(filters, duckingFilter(duck))
+	}
+	if cfg.EnhanceSpeech {
+		// Speech cleanup runs first so the mute below still silences fully
+		filters = append(filters, speechEnhanceFilters...)
+	}
+	if cfg.Normalize {
+		// Before the mute and volume ranges, so loudnorm can't lift them back up
+		filters = append(filters, loudnormFilter(cfg.LoudnessTarget))
+	}
+	muteSegments := cfg.MuteSegments
+	if cfg.MuteStart != "" && cfg.MuteEnd != "" {
+
+		startSec := toSeconds(cfg.MuteStart)
+		endSec := toSeconds(cfg.MuteEnd)
+
+		muteSegments = append([]Segment{{startSec, endSec}}, muteSegments...)
+	}
+	// Mute times are on the input's timeline, but -ss restarts it at zero
+	muteSegments = windowSegments(cfg, muteSegments)
+	if cfg.VolStart != "" && cfg.VolEnd != "" {
+		volSegments := windowSegments(cfg, []Segment{{toSeconds(cfg.VolStart), toSeconds(cfg.VolEnd)}})
+		if len(volSegments) > 0 {
+			filters = append(filters, volumeRangeFilter(volSegments, cfg.VolLevel))
+		}
+	}
+	if len(muteSegments) > 0 {
+		filters = append(filters, muteFilter(muteSegments, cfg.MuteFade))
+	}
+	return filters, muteSegments
+}
+
+// simpleCutArgs builds the ffmpeg command line for the main encode.
+func simpleCutArgs(cfg Config) []string {
+	inputArgs := getInputArgs(cfg)
+	if cfg.HWEncoder == "h264_vaapi" {
+		inputArgs = append([]string{"-vaapi_device", vaapiDevice}, inputArgs...)
+	}
+
+	// Build Filter Chain
+	filters, muteSegments := audioFilters(cfg)
+
+	args := inputArgs
+	// With a delay the audio comes from a second, time-shifted copy of the input
+	audioInput := 0
+	inputCount := 1
+	if cfg.AudioDelay != 0 {
+		args = append(args, "-itsoffset", fmt.Sprintf("%.3f", cfg.AudioDelay))
+		args = append(args, getInputArgs(cfg)...)
+		audioInput = 1
+		inputCount++
+	}
+
+	var videoFilters []string
+	if cfg.Crop != "" {
+		// Validated in main; cropping comes before any resizing
+		r, _ := parseCrop(cfg.Crop)
+		videoFilters = append(videoFilters, r.filter())
+	}
+	if cfg.SquareSize > 0 {
+		videoFilters = append(videoFilters, squareFilter(cfg.SquareSize, cfg.SquareMode, cfg.SquareColor))
+	}
+	if cfg.Resolution != "" {
+		videoFilters = append(videoFilters, resolutionFilter(cfg.Resolution))
+	}
+	if cfg.ScaleFilter != "" {
+		videoFilters = append(videoFilters, cfg.ScaleFilter)
+	}
+	if cfg.Subs != "" && cfg.SubsMode == "burn" {
+		// After cropping and scaling so the text is sized for the final frame
+		videoFilters = append(videoFilters, burnSubtitlesFilter(cfg.Subs))
+	}
+
+	var graphs []string
+	videoSource := "0:v?"
+	// The range is on the input's timeline; with -start it may even fall
+	// outside the output altogether
+	replaceRange := windowSegments(cfg, []Segment{{toSeconds(cfg.ReplaceStart), toSeconds(cfg.ReplaceEnd)}})
+	if cfg.ReplaceWith != "" && len(replaceRange) > 0 {
+		startSec, endSec := replaceRange[0].Start, replaceRange[0].End
+		// Where the clip's first frame lands; before zero when -start cuts
+		// into the range, so the clip plays on from the right point
+		shift := toSeconds(cfg.ReplaceStart)
+		if cfg.StartTime != "" {
+			shift -= toSeconds(cfg.StartTime)
+		}
+
+		graph := replaceFilterGraph(inputCount, shift, startSec, endSec)
+		if len(videoFilters) > 0 {
+			// Run the remaining video filters on the overlaid result
+			graph += "[ov];[ov]" + strings.Join(videoFilters, ",")
+			videoFilters = nil
+		}
+		args = append(args, replaceInputArgs(cfg.ReplaceWith)...)
+		inputCount++
+		graphs = append(graphs, graph+"[v]")
+		videoSource = "[v]"
+		// The replaced range is silenced too
+		filters = append(filters, fmt.Sprintf("volume=0:enable='between(t,%.3f,%.3f)'", startSec, endSec))
+	}
+
+	if cfg.Watermark != "" {
+		// Overlaying needs a second input, so this always goes through filter_complex
+		args = append(args, "-i", cfg.Watermark)
+		graphs = append(graphs, watermarkGraph(cfg, streamLabel(videoSource), strings.Join(videoFilters, ","), inputCount, "[wv]"))
+		inputCount++
+		videoSource, videoFilters = "[wv]", nil
+	}
+
+	audioSource := fmt.Sprintf("%d:a?", audioInput)
+	if cfg.AudioMap != "" {
+		audioSource = mapOnInput(cfg.AudioMap, audioInput)
+	}
+	if len(cfg.MixTracks) > 0 || cfg.DuckVoice != "" {
+		var chain []string
+		label := "[" + strings.TrimSuffix(audioSource, "?") + "]"
+		if len(cfg.MixTracks) > 0 {
+			chain = append(chain, mixAudioGraph(audioInput, cfg.MixTracks, cfg.MixWeights)+"[mixed]")
+			label = "[mixed]"
+		}
+		if cfg.DuckVoice != "" {
+			args = append(args, "-i", cfg.DuckVoice)
+			chain = append(chain, sidechainDuckGraph(label, inputCount)+"[ducked]")
+			inputCount++
+			label = "[ducked]"
+		}
+		// Mute/enhance the combined track rather than each source
+		tail := "anull"
+		if len(filters) > 0 {
+			tail = strings.Join(filters, ",")
+			filters = nil
+		}
+		chain = append(chain, label+tail+"[a]")
+		graphs = append(graphs, strings.Join(chain, ";"))
+		audioSource = "[a]"
+	}
+
+	if cfg.Beep && len(muteSegments) > 0 && (audioSource != fmt.Sprintf("%d:a?", audioInput) || hasAudioStream(cfg)) {
+		// The tone goes over the finished mute, before anything shifts its timeline
+		if !strings.HasPrefix(audioSource, "[") {
+			tail := "anull"
+			if len(filters) > 0 {
+				tail = strings.Join(filters, ",")
+				filters = nil
+			}
+			graphs = append(graphs, streamLabel(audioSource)+tail+"[muted]")
+			audioSource = "[muted]"
+		}
+		graphs = append(graphs, beepGraph(audioSource, muteSegments, cfg.BeepFreq, "[beeped]"))
+		audioSource = "[beeped]"
+	}
+
+	if cfg.RemoveStart != "" {
+		// Times are on the input's timeline; -start has already been seeked past
+		offset := 0.0
+		if cfg.StartTime != "" {
+			offset = toSeconds(cfg.StartTime)
+		}
+		cutStart := toSeconds(cfg.RemoveStart) - offset
+		cutEnd := toSeconds(cfg.RemoveEnd) - offset
+
+		graphs = append(graphs, removeSectionGraph(false, streamLabel(videoSource), strings.Join(videoFilters, ","), cutStart, cutEnd, cfg.CutXfade, "[cv]"))
+		videoSource, videoFilters = "[cv]", nil
+		if audioSource != fmt.Sprintf("%d:a?", audioInput) || hasAudioStream(cfg) {
+			graphs = append(graphs, removeSectionGraph(true, streamLabel(audioSource), strings.Join(filters, ","), cutStart, cutEnd, cfg.CutXfade, "[ca]"))
+			audioSource, filters = "[ca]", nil
+		}
+	}
+
+	if len(cfg.SilenceCuts) > 0 {
+		// After the mute/replace ranges, which are timed before the cuts
+		cuts := windowSegments(cfg, cfg.SilenceCuts)
+		video, audio := silenceCutFilters(cuts)
+		chainFilter(&graphs, &videoSource, &videoFilters, video, "[tv]")
+		chainFilter(&graphs, &audioSource, &filters, audio, "[ta]")
+	}
+
+	if cfg.IntroSlate != "" || cfg.SlateImage != "" {
+		fps := 30.0
+		if _, _, probed, err := probeVideoGeometry(cfg); err == nil {
+			fps = probed
+		}
+		args = append(args, slateInputArgs(cfg, fps)...)
+		audio := ""
+		if audioSource != fmt.Sprintf("%d:a?", audioInput) || hasAudioStream(cfg) {
+			audio = streamLabel(audioSource)
+		}
+		graphs = append(graphs, slateGraph(cfg, inputCount, streamLabel(videoSource), strings.Join(videoFilters, ","), audio, strings.Join(filters, ",")))
+		inputCount += 2
+		videoSource, videoFilters = "[sv]", nil
+		if audio != "" {
+			audioSource, filters = "[sa]", nil
+		}
+	}
+
+	if cfg.Speed > 0 && cfg.Speed != 1 {
+		// Last, so mute/cut times above still refer to the original timeline
+		chainFilter(&graphs, &videoSource, &videoFilters, speedVideoFilter(cfg.Speed), "[spv]")
+		chainFilter(&graphs, &audioSource, &filters, atempoChain(cfg.Speed), "[spa]")
+	}
+	if cfg.FadeIn > 0 || cfg.FadeOut > 0 {
+		// On the finished timeline, so the fade-out lands on the real end
+		chainFilter(&graphs, &videoSource, &videoFilters, fadeFilter(cfg.FadeIn, cfg.FadeOut, cfg.ExpectedDuration), "[fv]")
+	}
+	if cfg.HWEncoder == "h264_vaapi" {
+		// Filters run in system memory; the frames go up to the GPU last
+		chainFilter(&graphs, &videoSource, &videoFilters, vaapiUpload, "[hw]")
+	}
+
+	subsInput := -1
+	if cfg.Subs != "" && cfg.SubsMode == "soft" {
+		args = append(args, "-i", cfg.Subs)
+		subsInput = inputCount
+		inputCount++
+	}
+
+	chapterInput := -1
+	if cfg.ImportChapters != "" {
+		args = append(args, "-f", "ffmetadata", "-i", cfg.ImportChapters)
+		chapterInput = inputCount
+		inputCount++
+	}
+
+	if len(graphs) > 0 {
+		args = append(args, "-filter_complex", strings.Join(graphs, ";"))
+	}
+	if chapterInput >= 0 {
+		args = append(args, "-map_chapters", strconv.Itoa(chapterInput))
+	}
+	if len(graphs) > 0 || cfg.AudioMap != "" || audioInput != 0 || subsInput >= 0 {
+		args = append(args, "-map", videoSource, "-map", audioSource)
+	}
+	if subsInput >= 0 {
+		args = append(args, "-map", fmt.Sprintf("%d:s", subsInput), "-c:s", subtitleCodec(cfg.OutputFile))
+	}
+	args = append(args, videoCodecArgs(cfg)...)
+	args = append(args, audioEncodeArgs(cfg)...)
+
+	if cfg.StripMetadata {
+		args = append(args, stripMetadataArgs...)
+	}
+
+	if len(videoFilters) > 0 {
+		args = append(args, "-vf", strings.Join(videoFilters, ","))
+	}
+	if len(filters) > 0 {
+		args = append(args, "-af", strings.Join(filters, ","))
+	}
+
+	return append(args, "-y", cfg.OutputFile)
+}
+
+// muteFilter silences every segment with a single volume filter. With a
+// fade, each segment instead ramps down over the fade before it and back up
+// over the fade after it; the fade is capped at half the segment's length.
+func muteFilter(segments []Segment, fade float64) string {
+	if fade > 0 {
+		gains := make([]string, len(segments))
+		for i, seg := range segments {
+			f := min(fade, (seg.End-seg.Start)/2)
+			if f <= 0 {
+				gains[i] = fmt.Sprintf("(1-between(t,%.3f,%.3f))", seg.Start, seg.End)
+				continue
+			}
+			// 1 well outside the segment, linear ramps either side, 0 inside
+			gains[i] = fmt.Sprintf("clip(max((%.3f-t)/%.3f,(t-%.3f)/%.3f),0,1)", seg.Start, f, seg.End, f)
+		}
+		return fmt.Sprintf("volume='%s':eval=frame", strings.Join(gains, "*"))
+	}
+
+	return volumeRangeFilter(segments, 0)
+}
+
+// volumeRangeFilter scales the audio to level during every segment; a
+// level of 0 is a mute.
+func volumeRangeFilter(segments []Segment, level float64) string {
+	ranges := make([]string, len(segments))
+	for i, seg := range segments {
+		ranges[i] = fmt.Sprintf("between(t,%.3f,%.3f)", seg.Start, seg.End)
+	}
+	return fmt.Sprintf("volume=%g:enable='%s'", level, strings.Join(ranges, "+"))
+}
+
+// maxVolumeLevel caps -vol-level; beyond this it's clipping, not adjusting.
+const maxVolumeLevel = 4.0
+
+// timeFieldPattern is one colon-separated field of a timestamp.
+var timeFieldPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// ParseTimeToSeconds parses "SS", "MM:SS" or "HH:MM:SS" (each optionally
+// with a fractional part, e.g. "00:01:23.456") into seconds. Minutes and
+// seconds after the first field must be below 60. Go-style durations such
+// as "1h2m3s" or "1m30.5s" are accepted too.
+func ParseTimeToSeconds(ts string) (float64, error) {
+	// Try simple float first
+	if val, err := strconv.ParseFloat(ts, 64); err == nil {
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return 0, fmt.Errorf("invalid time '%s'", ts)
+		}
+		return val, nil
+	}
+
+	if !strings.Contains(ts, ":") && strings.ContainsAny(ts, "hms") {
+		d, err := time.ParseDuration(ts)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time '%s': expected a duration like 1h2m3s", ts)
+		}
+		return d.Seconds(), nil
+	}
+
+	// Try HH:MM:SS or MM:SS
+	parts := strings.Split(ts, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time '%s': %d fields, expected at most HH:MM:SS", ts, len(parts))
+	}
+	var seconds float64
+	for i, part := range parts {
+		if !timeFieldPattern.MatchString(part) {
+			return 0, fmt.Errorf("invalid time '%s': '%s' is not a number", ts, part)
+		}
+		val, _ := strconv.ParseFloat(part, 64)
+		if i > 0 && val >= 60 {
+			unit := "seconds"
+			if i < len(parts)-1 {
+				unit = "minutes"
+			}
+			return 0, fmt.Errorf("invalid time '%s': %s must be below 60", ts, unit)
+		}
+		seconds = seconds*60 + val
+	}
+	return seconds, nil
+}
+
+// toSeconds is ParseTimeToSeconds for values already checked by
+// validateTimeFlags (or a stricter pattern); anything malformed reads as 0.
+func toSeconds(ts string) float64 {
+	seconds, _ := ParseTimeToSeconds(ts)
+	return seconds
+}
+
+// ffmpegTime renders a validated time flag as plain seconds, since ffmpeg
+// doesn't understand every form we accept (e.g. "1m30s").
+func ffmpegTime(ts string) string {
+	return strconv.FormatFloat(toSeconds(ts), 'f', -1, 64)
+}
+
+// strictTimePattern accepts exactly SS, MM:SS or HH:MM:SS, each optionally
+// followed by a fractional part (.mmm).
+var strictTimePattern = regexp.MustCompile(`^(\d+|\d+:[0-5]\d|\d+:[0-5]\d:[0-5]\d)(\.\d+)?$`)
+
+// parseTimeStrict is the -strict-time counterpart of ParseTimeToSeconds: it
+// refuses anything outside the three documented shapes instead of guessing.
+func parseTimeStrict(ts string) (float64, error) {
+	if !strictTimePattern.MatchString(ts) {
+		if n := strings.Count(ts, ":"); n > 2 {
+			return 0, fmt.Errorf("invalid time '%s': %d fields, expected at most HH:MM:SS", ts, n+1)
+		}
+		return 0, fmt.Errorf("invalid time '%s': expected SS, MM:SS or HH:MM:SS (optionally with .mmm)", ts)
+	}
+	return ParseTimeToSeconds(ts)
+}
+
+// validateTimeFlags runs every time-valued option through parse, which is
+// parseTimeStrict under -strict-time and ParseTimeToSeconds otherwise.
+func validateTimeFlags(cfg Config, parse func(string) (float64, error)) error {
+	fields := []struct {
+		flag  string
+		value string
+	}{
+		{"-start", cfg.StartTime},
+		{"-end", cfg.EndTime},
+		{"-duration", cfg.Duration},
+		{"-skip-intro", cfg.SkipIntro},
+		{"-skip-outro", cfg.SkipOutro},
+		{"-mute-start", cfg.MuteStart},
+		{"-mute-end", cfg.MuteEnd},
+		{"-vol-start", cfg.VolStart},
+		{"-vol-end", cfg.VolEnd},
+		{"-replace-start", cfg.ReplaceStart},
+		{"-replace-end", cfg.ReplaceEnd},
+		{"-remove-start", cfg.RemoveStart},
+		{"-remove-end", cfg.RemoveEnd},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if _, err := parse(f.value); err != nil {
+			return fmt.Errorf("%s: %w", f.flag, err)
+		}
+	}
+	return nil
+}
+
+// runFFmpeg runs ffmpeg with args. On failure the returned error carries
+// the tail of ffmpeg's own error output so the cause is visible.
+func runFFmpeg(cfg Config, args []string) error {
+	if cfg.Explain {
+		explainArgs(cfg.FfmpegBin, args)
+	}
+	if cfg.DryRun {
+		fmt.Println(shellCommand(cfg.FfmpegBin, args))
+		return nil
+	}
+
+	// Write to a temp name beside the output and only rename once ffmpeg
+	// succeeds, so nothing ever sees a half-written file under the real name.
+	output, tmpOutput := "", ""
+	if len(args) > 0 && args[len(args)-1] != "-" {
+		output = args[len(args)-1]
+	}
+	if !cfg.NoAtomic && output != "" {
+		tmpOutput = atomicTempPath(output)
+		args = append(args[:len(args)-1:len(args)-1], tmpOutput)
+	}
+	// Under -no-atomic ffmpeg writes the real file, which may be one that
+	// was there before this run (-y) and isn't ours to delete.
+	_, statErr := os.Stat(output)
+	createdOutput := output != "" && os.IsNotExist(statErr)
+
+	// Stage is only set once the job proper starts; quick helper runs before
+	// that (benchmark, auto-CRF samples) stay quiet.
+	showProgress := !cfg.Verbose && (cfg.ExpectedDuration > 0 || cfg.Stage > 0)
+	if showProgress {
+		args = append(append([]string{}, progressArgs...), args...)
+	}
+
+	if !showProgress && minLevel > levelInfo {
+		args = append([]string{"-hide_banner", "-loglevel", "error"}, args...)
+	}
+	debugf("Running: %s\n", shellCommand(cfg.FfmpegBin, args))
+
+	ctx := jobContext(cfg)
+	cmd := exec.CommandContext(ctx, cfg.FfmpegBin, args...)
+	stderr := &tailBuffer{max: ffmpegErrorTail}
+	if cfg.Verbose {
+		cmd.Stdout = os.Stdout
+	}
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	var err error
+	if showProgress {
+		err = runWithProgress(cmd, cfg)
+	} else {
+		err = cmd.Run()
+	}
+	if err != nil {
+		// Never leave a truncated file behind for scripts to mistake for a
+		// result; under -no-atomic the real file is the partial one.
+		if tmpOutput != "" {
+			os.Remove(tmpOutput)
+		} else if createdOutput {
+			os.Remove(output)
+		}
+		if ctx.Err() != nil {
+			return cancelError(cfg, ctx)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("ffmpeg failed (%w):\n%s", err, msg)
+		}
+		return fmt.Errorf("ffmpeg failed: %w", err)
+	}
+
+	if tmpOutput != "" {
+		if err := os.Rename(tmpOutput, output); err != nil {
+			os.Remove(tmpOutput)
+			return fmt.Errorf("cannot move output into place: %w", err)
+		}
+	}
+	return nil
+}
+
+// ffmpegErrorTail is how much of ffmpeg's stderr is kept for error messages.
+const ffmpegErrorTail = 2048
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string { return string(t.buf) }
+
+// atomicTempPath returns the in-progress name for output. The ".tmp" goes
+// before the extension because ffmpeg picks the container from it.
+func atomicTempPath(output string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + ".tmp" + ext
+}
+
+func resolveBinary(name string) string {
+	exePath, err := os.Executable()
+	if err == nil {
+		binPath := filepath.Join(filepath.Dir(exePath), "bin", name)
+		if _, err := os.Stat(binPath); err == nil {
+			return binPath
+		}
+		if _, err := os.Stat(binPath + ".exe"); err == nil {
+			return binPath + ".exe"
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err == nil {
+		binPath := filepath.Join(cwd, "bin", name)
+		if _, err := os.Stat(binPath); err == nil {
+			return binPath
+		}
+		if _, err := os.Stat(binPath + ".exe"); err == nil {
+			return binPath + ".exe"
+		}
+	}
+
+	path, _ := exec.LookPath(name)
+	return path
+}
+
+func printStats(cfg Config, result Result) {
+	var size int64
+	info, statErr := os.Stat(cfg.OutputFile)
+	if statErr == nil {
+		size = info.Size()
+	}
+
+	if cfg.JSON {
+		// One line, so scripts can pick it off the end of the log
+		json.NewEncoder(os.Stdout).Encode(newJobSummary(cfg, result, size))
+		return
+	}
+
+	logln("\n Done!")
+	logf("Output: %s\n", cfg.OutputFile)
+	for _, out := range result.ExtraOutputs {
+		logf("Also:   %s\n", out)
+	}
+	switch in := inputSize(cfg); {
+	case statErr != nil:
+		logf("Size:   unknown, the output could not be read (%v)\n", statErr)
+	case in > 0:
+		ratio := float64(size) / float64(in)
+		logf("Size:   %s (%.0f%% of the input)\n", formatSize(size), ratio*100)
+	default:
+		logf("Size:   %s\n", formatSize(size))
+	}
+	logf("Took:   %s\n", result.Elapsed.Round(100*time.Millisecond))
+	for _, out := range append([]string{cfg.OutputFile}, result.ExtraOutputs...) {
+		if hash, ok := result.Hashes[out]; ok {
+			fmt.Printf("SHA-256: %s  %s\n", hash, out)
+		}
+	}
+}
+
+// jobSummary is the -json result of a run.
+type jobSummary struct {
+	Input          string            `json:"input"`
+	Output         string            `json:"output"`
+	ExtraOutputs   []string          `json:"extra_outputs"`
+	Mode           string            `json:"mode"`
+	ElapsedSeconds float64           `json:"elapsed_seconds"`
+	OutputBytes    int64             `json:"output_bytes"`
+	SHA256         map[string]string `json:"sha256,omitempty"` // Output path -> hash, with -hash
+}
+
+func newJobSummary(cfg Config, result Result, size int64) jobSummary {
+	out := jobSummary{cfg.InputFile, cfg.OutputFile, result.ExtraOutputs, jobMode(cfg), result.Elapsed.Seconds(), size, result.Hashes}
+	if out.ExtraOutputs == nil {
+		out.ExtraOutputs = []string{}
+	}
+	return out
+}
+
+// inputSize is the size of the input file in bytes, or 0 for generated
+// sources, capture devices and anything else that isn't a regular file.
+func inputSize(cfg Config) int64 {
+	info, err := os.Stat(cfg.InputFile)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
+
+// jobMode names what the run did, following the dispatch order in main.
+func jobMode(cfg Config) string {
+	switch {
+	case len(cfg.ConcatFiles) > 0:
+		return "concat"
+	case cfg.SplitSilence:
+		return "split-silence"
+	case cfg.ExtractMP3 && cfg.SplitByChapter:
+		return "split-chapters"
+	case cfg.ExtractMP3:
+		return "audio"
+	case cfg.Repair:
+		return "repair"
+	case cfg.ExportWebP:
+		return "webp"
+	case cfg.ExportGIF:
+		return "gif"
+	case cfg.StreamCopy:
+		return "copy"
+	}
+	return "cut"
+}
+
+// confirm asks a yes/no question on stdin; anything but y/yes is a no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+func interactiveMode() Config {
+	scanner := bufio.NewScanner(os.Stdin)
+	cfg := Config{}
+
+	// 1. Input File
+	fmt.Print("Enter input video file path or YouTube URL: ")
+	if scanner.Scan() {
+		cfg.InputFile = strings.TrimSpace(scanner.Text())
+	}
+
+	// 2. Mode Selection
+	fmt.Println("Select Mode:")
+	fmt.Println("1. Cut (Trim video)")
+	fmt.Println("2. Mute (Mute a section)")
+	fmt.Println("3. Extract MP3")
+	fmt.Print("Enter choice (1, 2, or 3): ")
+	var mode string
+	if scanner.Scan() {
+		mode = strings.TrimSpace(scanner.Text())
+	}
+
+	if mode == "1" {
+		fmt.Println("--- Cut Mode ---")
+		fmt.Print("Enter Start Time (e.g., 00:05 or 5): ")
+		if scanner.Scan() {
+			cfg.StartTime = strings.TrimSpace(scanner.Text())
+		}
+		fmt.Print("Enter End Time (e.g., 00:10 or 10): ")
+		if scanner.Scan() {
+			cfg.EndTime = strings.TrimSpace(scanner.Text())
+		}
+	} else if mode == "2" {
+		fmt.Println("--- Mute Mode ---")
+		fmt.Print("Enter Start Time to Mute (e.g., 00:05 or 5): ")
+		if scanner.Scan() {
+			cfg.MuteStart = strings.TrimSpace(scanner.Text())
+		}
+		fmt.Print("Enter End Time to Mute (e.g., 00:10 or 10): ")
+		if scanner.Scan() {
+			cfg.MuteEnd = strings.TrimSpace(scanner.Text())
+		}
+	} else if mode == "3" {
+		fmt.Println("--- MP3 Extraction Mode ---")
+		cfg.ExtractMP3 = true
+	} else {
+		errorln("Invalid mode selected. Exiting.")
+	}
+
+	return cfg
+}
@@ -0,0 +1,185 @@
+package mutecut
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseTimeToSeconds(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"90", 90},
+		{"1:30", 90},
+		{"1m30s", 90},
+		{"00:01:30", 90},
+		{"01:02:03.5", 3723.5},
+		{"1h2m3.5s", 3723.5},
+		{"00:01:23.456", 83.456},
+		{"2.25", 2.25},
+		{"45s", 45},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeToSeconds(tt.in)
+		if err != nil {
+			t.Errorf("ParseTimeToSeconds(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimeToSeconds(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeToSecondsMalformed(t *testing.T) {
+	malformed := []string{
+		"",         // empty
+		"abc",      // not a number
+		"00:ab:30", // non-numeric minutes
+		"1:x",      // non-numeric seconds
+		"1::30",    // empty field
+		"1:2:3:4",  // too many fields
+		"00:60",    // seconds out of range
+		"00:60:00", // minutes out of range
+		"1:-5",     // negative field
+		"1h2x",     // bad duration unit
+		"NaN",      // not a finite number
+		"Inf",      // not a finite number
+	}
+	for _, ts := range malformed {
+		if got, err := ParseTimeToSeconds(ts); err == nil {
+			t.Errorf("ParseTimeToSeconds(%q) = %v, want an error", ts, got)
+		}
+	}
+}
+
+func TestGetInputArgsSeconds(t *testing.T) {
+	cfg := Config{InputFile: "in.mp4", StartTime: "1m30s", Duration: "1:00"}
+	args := getInputArgs(cfg)
+	want := []string{"-ss", "90", "-t", "60"}
+	for i, w := range want {
+		if args[i] != w {
+			t.Fatalf("getInputArgs = %v, want prefix %v", args, want)
+		}
+	}
+}
+
+func TestParseTimeStrictRejects(t *testing.T) {
+	rejected := []string{
+		"",            // empty
+		"1:2:3:4",     // four fields
+		"0:0:0:0:1",   // five fields
+		"1:5",         // single-digit seconds
+		"1:02:3",      // single-digit seconds
+		"1:60",        // seconds out of range
+		"1:61:00",     // minutes out of range
+		"1m30s",       // Go duration
+		"90s",         // unit suffix
+		"-5",          // negative
+		"+5",          // sign
+		"1e3",         // exponent
+		".5",          // no leading digits
+		"5.",          // no fractional digits
+		"1:30.",       // no fractional digits
+		" 90",         // whitespace
+		"1:30 ",       // whitespace
+		"00:ab:30",    // non-numeric field
+		"1.5:30",      // fractional minutes
+		"1::30",       // empty field
+		"01:02:03.5x", // trailing junk
+	}
+	for _, ts := range rejected {
+		if got, err := parseTimeStrict(ts); err == nil {
+			t.Errorf("parseTimeStrict(%q) = %v, want an error", ts, got)
+		}
+	}
+}
+
+func TestParseTimeStrictAccepts(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"90", 90},
+		{"90.25", 90.25},
+		{"1:30", 90},
+		{"01:30.5", 90.5},
+		{"01:02:03", 3723},
+		{"01:02:03.500", 3723.5},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeStrict(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseTimeStrict(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestMuteFilterFade(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []Segment
+		fade     float64
+		want     string
+	}{
+		{
+			name:     "3s mute, 0.5s fade",
+			segments: []Segment{{10, 13}},
+			fade:     0.5,
+			want:     "volume='clip(max((10.000-t)/0.500,(t-13.000)/0.500),0,1)':eval=frame",
+		},
+		{
+			name:     "fade clamped to half the segment",
+			segments: []Segment{{10, 10.4}},
+			fade:     0.5,
+			want:     "volume='clip(max((10.000-t)/0.200,(t-10.400)/0.200),0,1)':eval=frame",
+		},
+		{
+			name:     "no fade",
+			segments: []Segment{{10, 13}},
+			fade:     0,
+			want:     "volume=0:enable='between(t,10.000,13.000)'",
+		},
+	}
+	for _, tt := range tests {
+		if got := muteFilter(tt.segments, tt.fade); got != tt.want {
+			t.Errorf("%s: muteFilter = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunFFmpegNoAtomicKeepsExistingOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ffmpeg")
+	}
+	dir := t.TempDir()
+	// Stands in for an ffmpeg that starts writing the output, then fails
+	fake := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nfor last; do :; done\necho partial > \"$last\"\nexit 1\n"
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{FfmpegBin: fake, NoAtomic: true}
+
+	existing := filepath.Join(dir, "existing.mp4")
+	if err := os.WriteFile(existing, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFFmpeg(cfg, []string{"-y", existing}); err == nil {
+		t.Fatal("runFFmpeg succeeded with a failing ffmpeg")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("a file that was there before the run was deleted: %v", err)
+	}
+
+	created := filepath.Join(dir, "new.mp4")
+	if err := runFFmpeg(cfg, []string{"-y", created}); err == nil {
+		t.Fatal("runFFmpeg succeeded with a failing ffmpeg")
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("the partial output of the failed run was kept: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseConcatList splits -concat's comma-separated list of files and checks
+// they all exist.
+func parseConcatList(list string) ([]string, error) {
+	var files []string
+	for _, f := range strings.Split(list, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	if len(files) < 2 {
+		return nil, fmt.Errorf("-concat needs at least two files, separated by commas")
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read '%s': %w", f, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("'%s' is a directory", f)
+		}
+	}
+	return files, nil
+}
+
+// streamSignature summarises what the concat demuxer needs to match across
+// files: each stream's type and codec, plus the video size and frame rate.
+func streamSignature(cfg Config, file string) (string, bool, error) {
+	cfg.InputFile = file
+	streams, err := probeStreams(cfg)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", file, err)
+	}
+	var parts []string
+	hasAudio := false
+	for _, s := range streams {
+		part := s.CodecType + ":" + s.CodecName
+		switch s.CodecType {
+		case "video":
+			part += fmt.Sprintf(":%dx%d@%s", s.Width, s.Height, s.FrameRate)
+		case "audio":
+			hasAudio = true
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ","), hasAudio, nil
+}
+
+// concatFiles joins cfg.ConcatFiles into cfg.OutputFile. Files with
+// identical streams go through the concat demuxer as a stream copy; mixed
+// inputs, or a -format/-vcodec/-acodec that needs other codecs, are scaled
+// to the first file's size and frame rate and re-encoded through the
+// concat filter.
+func concatFiles(cfg Config) error {
+	files := cfg.ConcatFiles
+	first, allAudio := "", true
+	same := true
+	total := 0.0
+	for i, f := range files {
+		sig, hasAudio, err := streamSignature(cfg, f)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			first = sig
+		} else if sig != first {
+			same = false
+		}
+		allAudio = allAudio && hasAudio
+
+		fileCfg := cfg
+		fileCfg.InputFile = f
+		if d, err := GetDuration(fileCfg); err == nil {
+			total += d
+		}
+	}
+	cfg.ExpectedDuration = total
+
+	if same && !concatNeedsEncode(cfg) {
+		logf("Joining %d files with matching streams (stream copy)...\n", len(files))
+		return concatDemux(cfg)
+	}
+	if same {
+		logf("Re-encoding %d files to join them in the requested codecs...\n", len(files))
+	} else {
+		logf("Files differ in codec, size or frame rate; re-encoding %d files to join them...\n", len(files))
+	}
+	return concatFilter(cfg, allAudio)
+}
+
+// concatNeedsEncode reports whether the codecs asked for (by -format,
+// -quality, -vcodec or -acodec) rule out a stream copy of the inputs.
+func concatNeedsEncode(cfg Config) bool {
+	if outputContainers[cfg.Format].VideoCodec != "" {
+		return true
+	}
+	return (cfg.VideoCodec != "" && cfg.VideoCodec != "copy") ||
+		(cfg.AudioCodec != "" && cfg.AudioCodec != "copy")
+}
+
+// concatAudioArgs is the audio encoding for the concat filter's output.
+// Filtered audio can't be stream copied, so -acodec copy falls back to
+// the container's usual codec.
+func concatAudioArgs(cfg Config) []string {
+	if cfg.AudioCodec == "copy" {
+		warnln("Warning: the joined audio is re-encoded; ignoring -acodec copy.")
+		cfg.AudioCodec = ""
+	}
+	return audioEncodeArgs(cfg)
+}
+
+func concatDemux(cfg Config) error {
+	list, err := os.CreateTemp("", "mutecut-concat-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(list.Name())
+	for _, f := range cfg.ConcatFiles {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			list.Close()
+			return err
+		}
+		fmt.Fprintf(list, "file '%s'\n", strings.ReplaceAll(abs, "'", `'\''`))
+	}
+	if err := list.Close(); err != nil {
+		return err
+	}
+
+	args := []string{
+		"-f", "concat", "-safe", "0", "-i", list.Name(),
+		"-map", "0", "-c", "copy",
+	}
+	if cfg.StripMetadata {
+		args = append(args, stripMetadataArgs...)
+	}
+	return runFFmpeg(cfg, append(args, "-y", cfg.OutputFile))
+}
+
+func concatFilter(cfg Config, withAudio bool) error {
+	firstCfg := cfg
+	firstCfg.InputFile = cfg.ConcatFiles[0]
+	width, height, fps, err := probeVideoGeometry(firstCfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", firstCfg.InputFile, err)
+	}
+	width, height = width&^1, height&^1 // x264 needs even sizes
+
+	var args, chains []string
+	var joined strings.Builder
+	for i, f := range cfg.ConcatFiles {
+		args = append(args, "-i", f)
+		chains = append(chains, fmt.Sprintf(
+			"[%d:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%g[v%d]",
+			i, width, height, width, height, fps, i))
+		fmt.Fprintf(&joined, "[v%d]", i)
+		if withAudio {
+			chains = append(chains, fmt.Sprintf("[%d:a]aformat=sample_rates=48000:channel_layouts=stereo[a%d]", i, i))
+			fmt.Fprintf(&joined, "[a%d]", i)
+		}
+	}
+
+	n, a := len(cfg.ConcatFiles), 0
+	maps := []string{"-map", "[v]"}
+	outs := "[v]"
+	if withAudio {
+		a, outs = 1, "[v][a]"
+		maps = append(maps, "-map", "[a]")
+	} else {
+		warnln("Warning: not every file has audio; the joined output will be silent.")
+	}
+	chains = append(chains, fmt.Sprintf("%sconcat=n=%d:v=1:a=%d%s", joined.String(), n, a, outs))
+
+	args = append(args, "-filter_complex", strings.Join(chains, ";"))
+	args = append(args, maps...)
+	if cfg.VideoCodec == "copy" {
+		warnln("Warning: the joined video is re-encoded; ignoring -vcodec copy.")
+		cfg.VideoCodec = ""
+	}
+	args = append(args, videoCodecArgs(cfg)...)
+	if withAudio {
+		args = append(args, concatAudioArgs(cfg)...)
+	}
+	if cfg.StripMetadata {
+		args = append(args, stripMetadataArgs...)
+	}
+	return runFFmpeg(cfg, append(args, "-y", cfg.OutputFile))
+}
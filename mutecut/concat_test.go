@@ -0,0 +1,42 @@
+package mutecut
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConcatNeedsEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{"defaults", Config{}, false},
+		{"mkv", Config{Format: "mkv"}, false},
+		{"webm", Config{Format: "webm", VideoCodec: "libvpx-vp9"}, true},
+		{"acodec", Config{AudioCodec: "libopus"}, true},
+		{"copy", Config{VideoCodec: "copy", AudioCodec: "copy"}, false},
+	}
+	for _, tt := range tests {
+		if got := concatNeedsEncode(tt.cfg); got != tt.want {
+			t.Errorf("%s: concatNeedsEncode = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConcatAudioArgs(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want []string
+	}{
+		{Config{}, []string{"-c:a", "aac", "-b:a", "192k"}},
+		{Config{Format: "webm"}, []string{"-c:a", "libopus", "-b:a", "192k"}},
+		{Config{AudioCodec: "libmp3lame"}, []string{"-c:a", "libmp3lame", "-b:a", "192k"}},
+		{Config{Format: "webm", AudioCodec: "copy"}, []string{"-c:a", "libopus", "-b:a", "192k"}},
+	}
+	for _, tt := range tests {
+		if got := concatAudioArgs(tt.cfg); !slices.Equal(got, tt.want) {
+			t.Errorf("concatAudioArgs(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
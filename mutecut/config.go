@@ -0,0 +1,103 @@
+package mutecut
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// envFlags maps environment variables onto the flags they stand in for, so
+// CI jobs can configure a run without building a command line.
+var envFlags = []struct {
+	env  string
+	flag string
+}{
+	{"MUTECUT_INPUT", "i"},
+	{"MUTECUT_OUTPUT", "o"},
+	{"MUTECUT_START", "start"},
+	{"MUTECUT_END", "end"},
+	{"MUTECUT_SKIP_INTRO", "skip-intro"},
+	{"MUTECUT_SKIP_OUTRO", "skip-outro"},
+	{"MUTECUT_MUTE_START", "mute-start"},
+	{"MUTECUT_MUTE_END", "mute-end"},
+	{"MUTECUT_PRESET", "preset"},
+	{"MUTECUT_CRF", "crf"},
+	{"MUTECUT_AUDIO_LANG", "audio-lang-select"},
+	{"MUTECUT_AUDIO_DELAY", "audio-delay"},
+	{"MUTECUT_URL", "url"},
+	{"MUTECUT_YT_MAX_DURATION", "yt-max-duration"},
+	{"MUTECUT_YT_QUALITY", "yt-quality"},
+}
+
+// explicitFlags reports which flags were given on the command line. The
+// config file and MUTECUT_* variables set values without marking them, so
+// they never show up here.
+func explicitFlags() map[string]bool {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// applyEnvDefaults fills in flags from MUTECUT_* variables. Flags passed on
+// the command line always win.
+func applyEnvDefaults(explicit map[string]bool) error {
+	for _, e := range envFlags {
+		value, ok := os.LookupEnv(e.env)
+		if !ok || explicit[e.flag] {
+			continue
+		}
+		if err := flag.Lookup(e.flag).Value.Set(value); err != nil { // Not flag.Set: see explicitFlags
+			return fmt.Errorf("invalid %s: %w", e.env, err)
+		}
+	}
+	return nil
+}
+
+// applyConfigFile fills in flags from a JSON file mapping flag names to
+// values, e.g. {"preset": "slow", "crf": 20, "mp3": true}. It runs before
+// applyEnvDefaults, so the precedence is defaults < config file < MUTECUT_*
+// < command line.
+func applyConfigFile(path string, explicit map[string]bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		name := strings.TrimLeft(key, "-")
+		if name == "config" || flag.Lookup(name) == nil {
+			return fmt.Errorf("config %s: unknown option '%s'", path, key)
+		}
+		if explicit[name] {
+			continue
+		}
+		var value string
+		switch v := values[key].(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Errorf("config %s: '%s' must be a string, number or boolean", path, key)
+		}
+		// Value.Set rather than flag.Set, so the option doesn't then look
+		// like it was given on the command line (see explicitFlags)
+		if err := flag.Lookup(name).Value.Set(value); err != nil {
+			return fmt.Errorf("config %s: invalid %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
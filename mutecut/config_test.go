@@ -0,0 +1,66 @@
+package mutecut
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyConfigFileNotExplicit(t *testing.T) {
+	value := testFlag(t, "test-config-value", "default")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"test-config-value": "from-config"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyConfigFile(path, map[string]bool{}); err != nil {
+		t.Fatalf("applyConfigFile: %v", err)
+	}
+	if value.String() != "from-config" {
+		t.Errorf("value = %q, want %q", value, "from-config")
+	}
+	if explicitFlags()["test-config-value"] {
+		t.Error("a config file value counts as given on the command line")
+	}
+}
+
+func TestApplyConfigFileUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"no-such-option": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfigFile(path, map[string]bool{}); err == nil {
+		t.Error("applyConfigFile accepted an unknown option")
+	}
+}
+
+func TestApplyEnvDefaultsNotExplicit(t *testing.T) {
+	preset := testFlag(t, "preset", "medium") // Normally defined by Main
+	t.Setenv("MUTECUT_PRESET", "veryslow")
+
+	if err := applyEnvDefaults(map[string]bool{}); err != nil {
+		t.Fatalf("applyEnvDefaults: %v", err)
+	}
+	if preset.String() != "veryslow" {
+		t.Errorf("preset = %q, want %q", preset, "veryslow")
+	}
+	if explicitFlags()["preset"] {
+		t.Error("a MUTECUT_* value counts as given on the command line")
+	}
+}
+
+// testFlag returns the string flag name, defining it on the command line's
+// flag set unless another test (or Main) already has. It's reset to value
+// when the test ends.
+func testFlag(t *testing.T, name, value string) flag.Value {
+	t.Helper()
+	f := flag.Lookup(name)
+	if f == nil {
+		flag.String(name, value, "")
+		f = flag.Lookup(name)
+	}
+	f.Value.Set(value)
+	t.Cleanup(func() { f.Value.Set(value) })
+	return f.Value
+}
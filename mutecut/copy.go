@@ -0,0 +1,80 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// copyConflicts names the options that need the picture or sound decoded,
+// which a -copy cut never does.
+func copyConflicts(cfg Config) []string {
+	checks := []struct {
+		set  bool
+		name string
+	}{
+		{cfg.MuteStart != "" || len(cfg.MuteSegments) > 0, "-mute-start/-mute-end"},
+		{cfg.VolStart != "", "-vol-start/-vol-end"},
+		{cfg.SquareSize > 0, "-square"},
+		{cfg.ReplaceWith != "", "-replace-with"},
+		{len(cfg.MixTracks) > 0, "-mix-audio"},
+		{len(cfg.DuckRanges) > 0 || cfg.DuckVoice != "", "-ducking-file/-ducking-voice"},
+		{cfg.PeakGain != 0, "-peak-normalize"},
+		{cfg.Normalize, "-normalize"},
+		{cfg.AudioDelay != 0, "-audio-delay/-auto-sync"},
+		{cfg.EnhanceSpeech, "-enhance-speech"},
+		{cfg.IntroSlate != "" || cfg.SlateImage != "", "-intro-slate/-slate-image"},
+		{cfg.Resolution != "", "-resolution"},
+		{cfg.ScaleFilter != "", "-scale"},
+		{cfg.Crop != "", "-crop"},
+		{cfg.Speed != 1, "-speed"},
+		{cfg.FadeIn > 0 || cfg.FadeOut > 0, "-fade-in/-fade-out"},
+		{cfg.RemoveStart != "", "-remove-start/-remove-end"},
+		{cfg.AutoMute, "-auto-mute/-trim-silence"},
+		{cfg.Subs != "", "-subs"},
+		{cfg.Watermark != "", "-watermark"},
+		{cfg.Format == "webm", "-format webm"},
+		{cfg.MaxFileSize > 0, "-max-size"},
+		{cfg.BitDepth == 10, "-bitdepth 10"},
+		{cfg.HWEncoder != "", "-hwaccel"},
+		{cfg.ExtractMP3, "-mp3"},
+		{cfg.ExportWebP, "-webp"},
+		{cfg.ExportGIF, "-gif"},
+		{cfg.SplitSilence, "-split-silence"},
+		{cfg.Repair, "-repair"},
+		{len(cfg.ConcatFiles) > 0, "-concat"},
+	}
+	var names []string
+	for _, c := range checks {
+		if c.set {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+func validateStreamCopy(cfg Config) error {
+	if conflicts := copyConflicts(cfg); len(conflicts) > 0 {
+		return fmt.Errorf("-copy only trims; it can't be combined with %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// copyCutArgs trims without re-encoding. Input seeking with -c copy starts
+// at the keyframe before -start, so the cut can land a little early.
+func copyCutArgs(cfg Config) []string {
+	args := getInputArgs(cfg)
+	if cfg.ImportChapters != "" {
+		args = append(args, "-f", "ffmetadata", "-i", cfg.ImportChapters, "-map_chapters", "1")
+	}
+	args = append(args, "-map", "0:v?")
+	if cfg.AudioMap != "" {
+		args = append(args, "-map", cfg.AudioMap)
+	} else {
+		args = append(args, "-map", "0:a?")
+	}
+	args = append(args, "-c", "copy", "-avoid_negative_ts", "make_zero")
+	if cfg.StripMetadata {
+		args = append(args, stripMetadataArgs...)
+	}
+	return append(args, "-y", cfg.OutputFile)
+}
@@ -0,0 +1,56 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cropRect is a -crop region in source pixels.
+type cropRect struct {
+	W, H, X, Y int
+}
+
+// parseCrop reads -crop's w:h:x:y.
+func parseCrop(spec string) (cropRect, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 4 {
+		return cropRect{}, fmt.Errorf("-crop must be w:h:x:y, got '%s'", spec)
+	}
+	var n [4]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || v < 0 {
+			return cropRect{}, fmt.Errorf("-crop values must be whole numbers of pixels, got '%s'", spec)
+		}
+		n[i] = v
+	}
+	r := cropRect{W: n[0], H: n[1], X: n[2], Y: n[3]}
+	if r.W == 0 || r.H == 0 || r.W%2 != 0 || r.H%2 != 0 {
+		return cropRect{}, fmt.Errorf("-crop width and height must be positive even numbers, got %dx%d", r.W, r.H)
+	}
+	return r, nil
+}
+
+func (r cropRect) filter() string {
+	return fmt.Sprintf("crop=%d:%d:%d:%d", r.W, r.H, r.X, r.Y)
+}
+
+// validateCrop checks -crop parses and fits inside the source picture.
+func validateCrop(cfg Config) error {
+	if cfg.Crop == "" {
+		return nil
+	}
+	r, err := parseCrop(cfg.Crop)
+	if err != nil {
+		return err
+	}
+	width, height, _, err := probeVideoGeometry(cfg)
+	if err != nil {
+		return err
+	}
+	if r.X+r.W > width || r.Y+r.H > height {
+		return fmt.Errorf("-crop %dx%d at %d,%d goes outside the %dx%d input", r.W, r.H, r.X, r.Y, width, height)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// removeSectionGraph drops [cutStart, cutEnd) from src and splices the two
+// remaining pieces back together. pre is an optional filter chain applied to
+// src before it is split. With xfade > 0 the pieces overlap by that many
+// seconds and cross-dissolve (video) / cross-fade (audio) instead of hard
+// cutting. The result is labelled out.
+func removeSectionGraph(audio bool, src, pre string, cutStart, cutEnd, xfade float64, out string) string {
+	split, trim, setpts, join := "split", "trim", "setpts", "concat=n=2:v=1:a=0"
+	if audio {
+		split, trim, setpts, join = "asplit", "atrim", "asetpts", "concat=n=2:v=0:a=1"
+	}
+	if xfade > 0 {
+		if audio {
+			join = fmt.Sprintf("acrossfade=d=%.3f", xfade)
+		} else {
+			join = fmt.Sprintf("xfade=transition=fade:duration=%.3f:offset=%.3f", xfade, cutStart-xfade)
+		}
+	}
+
+	p := "v"
+	if audio {
+		p = "a"
+	}
+	head := src
+	if pre != "" {
+		head += pre + ","
+	}
+	return strings.Join([]string{
+		fmt.Sprintf("%s%s=2[cut%s0][cut%s1]", head, split, p, p),
+		fmt.Sprintf("[cut%s0]%s=end=%.3f,%s=PTS-STARTPTS[cut%sa]", p, trim, cutStart, setpts, p),
+		fmt.Sprintf("[cut%s1]%s=start=%.3f,%s=PTS-STARTPTS[cut%sb]", p, trim, cutEnd, setpts, p),
+		fmt.Sprintf("[cut%sa][cut%sb]%s%s", p, p, join, out),
+	}, ";")
+}
+
+// removedSeconds is how much shorter -remove-start/-remove-end (and the
+// overlap of -cut-xfade) make the output.
+func removedSeconds(cfg Config) float64 {
+	if cfg.RemoveStart == "" {
+		return 0
+	}
+	return toSeconds(cfg.RemoveEnd) - toSeconds(cfg.RemoveStart) + cfg.CutXfade
+}
+
+// streamLabel turns a -map style specifier such as "0:a?" into a filter
+// graph input label.
+func streamLabel(spec string) string {
+	if strings.HasPrefix(spec, "[") {
+		return spec
+	}
+	return "[" + strings.TrimSuffix(spec, "?") + "]"
+}
+
+// chainFilter appends filter to the end of a stream's processing: as a
+// filter_complex step when source is already a graph label (the result is
+// relabelled out), otherwise onto its plain -vf/-af list.
+func chainFilter(graphs *[]string, source *string, plain *[]string, filter, out string) {
+	if strings.HasPrefix(*source, "[") {
+		*graphs = append(*graphs, *source+filter+out)
+		*source = out
+		return
+	}
+	*plain = append(*plain, filter)
+}
+
+func validateRemove(cfg Config) error {
+	if (cfg.RemoveStart == "") != (cfg.RemoveEnd == "") {
+		return fmt.Errorf("-remove-start and -remove-end must be used together")
+	}
+	if cfg.CutXfade < 0 {
+		return fmt.Errorf("-cut-xfade cannot be negative")
+	}
+	if cfg.RemoveStart == "" {
+		if cfg.CutXfade > 0 {
+			return fmt.Errorf("-cut-xfade needs -remove-start/-remove-end")
+		}
+		return nil
+	}
+
+	start, end := toSeconds(cfg.RemoveStart), toSeconds(cfg.RemoveEnd)
+	if end <= start {
+		return fmt.Errorf("-remove-end must be after -remove-start")
+	}
+	// The input's own length isn't probed here; trimRemovedEdge checks
+	// against it later
+	from, to := 0.0, 0.0
+	if cfg.StartTime != "" {
+		from = toSeconds(cfg.StartTime)
+	}
+	if cfg.EndTime != "" {
+		to = toSeconds(cfg.EndTime)
+	} else if cfg.Duration != "" {
+		to = from + toSeconds(cfg.Duration)
+	}
+	return checkRemoveInWindow(start, end, from, to)
+}
+
+// checkRemoveInWindow rejects a removed section that misses the output
+// window [from, to] altogether (to is 0 when the end isn't known).
+func checkRemoveInWindow(start, end, from, to float64) error {
+	if end <= from || (to > 0 && start >= to) {
+		return fmt.Errorf("-remove-start/-remove-end lie outside the part of the input being output")
+	}
+	return nil
+}
+
+// trimRemovedEdge handles a removed section that reaches the start or end
+// of the output. There is nothing to splice on that side, so it becomes a
+// plain -start or -end trim instead.
+func trimRemovedEdge(cfg *Config) error {
+	if cfg.RemoveStart == "" {
+		return nil
+	}
+	start, end := toSeconds(cfg.RemoveStart), toSeconds(cfg.RemoveEnd)
+
+	from, to := 0.0, 0.0 // to stays 0 if the end can't be worked out
+	if cfg.StartTime != "" {
+		from = toSeconds(cfg.StartTime)
+	}
+	if cfg.EndTime != "" {
+		to = toSeconds(cfg.EndTime)
+	} else if cfg.Duration != "" {
+		to = from + toSeconds(cfg.Duration)
+	} else if duration, err := GetDuration(*cfg); err == nil {
+		to = duration
+	}
+
+	if err := checkRemoveInWindow(start, end, from, to); err != nil {
+		return err
+	}
+	atStart := start <= from && end > from
+	atEnd := to > 0 && end >= to && start < to
+	switch {
+	case atStart && atEnd:
+		return fmt.Errorf("-remove-start/-remove-end cover the whole output")
+	case atStart:
+		logf("Removed section begins the output; starting at %s instead.\n", cfg.RemoveEnd)
+		cfg.StartTime = cfg.RemoveEnd
+	case atEnd:
+		logf("Removed section ends the output; ending at %s instead.\n", cfg.RemoveStart)
+		cfg.EndTime, cfg.Duration = cfg.RemoveStart, ""
+	default:
+		if cfg.CutXfade > start-from {
+			return fmt.Errorf("-cut-xfade %.3fs is longer than the part kept before the cut", cfg.CutXfade)
+		}
+		return nil
+	}
+
+	if cfg.CutXfade > 0 {
+		warnln("Warning: -cut-xfade has no join to fade over; ignoring it.")
+		cfg.CutXfade = 0
+	}
+	cfg.RemoveStart, cfg.RemoveEnd = "", ""
+	return nil
+}
+
+// hasAudioStream reports whether the input has any audio to cut.
+func hasAudioStream(cfg Config) bool {
+	streams, err := probeStreams(cfg)
+	if err != nil {
+		return true // Let ffmpeg report the real problem
+	}
+	for _, s := range streams {
+		if s.CodecType == "audio" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package mutecut
+
+import "testing"
+
+func TestTrimRemovedEdge(t *testing.T) {
+	tests := []struct {
+		name                   string
+		start, end             string // -start/-end
+		removeStart, removeEnd string
+		wantErr                bool
+		wantStart, wantEnd     string
+		wantRemoveKept         bool
+	}{
+		{"before window", "10", "30", "0", "5", true, "", "", false},
+		{"after window", "", "10", "20", "30", true, "", "", false},
+		{"leading overlap", "10", "30", "5", "15", false, "15", "30", false},
+		{"trailing overlap", "0", "30", "25", "40", false, "0", "25", false},
+		{"inside", "10", "30", "15", "20", false, "10", "30", true},
+	}
+	for _, tt := range tests {
+		cfg := Config{StartTime: tt.start, EndTime: tt.end, RemoveStart: tt.removeStart, RemoveEnd: tt.removeEnd}
+		if err := validateRemove(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateRemove error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		err := trimRemovedEdge(&cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: trimRemovedEdge error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if cfg.StartTime != tt.wantStart || cfg.EndTime != tt.wantEnd {
+			t.Errorf("%s: window = %q-%q, want %q-%q", tt.name, cfg.StartTime, cfg.EndTime, tt.wantStart, tt.wantEnd)
+		}
+		if kept := cfg.RemoveStart != ""; kept != tt.wantRemoveKept {
+			t.Errorf("%s: remove range kept = %v, want %v", tt.name, kept, tt.wantRemoveKept)
+		}
+	}
+}
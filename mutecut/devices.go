@@ -0,0 +1,162 @@
+package mutecut
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// captureDevice is a camera or microphone ffmpeg can record from.
+type captureDevice struct {
+	Kind string // "video" or "audio"
+	ID   string // what to pass to ffmpeg's -i
+	Name string
+}
+
+// listCaptureDevices asks ffmpeg for the capture devices on this platform:
+// AVFoundation on macOS, DirectShow on Windows, V4L2/ALSA on Linux.
+func listCaptureDevices(cfg Config) ([]captureDevice, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		out := ffmpegStderr(cfg, "-f", "avfoundation", "-list_devices", "true", "-i", "")
+		return parseAVFoundationDevices(out), nil
+	case "windows":
+		out := ffmpegStderr(cfg, "-f", "dshow", "-list_devices", "true", "-i", "dummy")
+		return parseDShowDevices(out), nil
+	case "linux":
+		var devices []captureDevice
+		paths, _ := filepath.Glob("/dev/video*")
+		for _, p := range paths {
+			devices = append(devices, captureDevice{Kind: "video", ID: p, Name: filepath.Base(p)})
+		}
+		out := ffmpegStdout(cfg, "-sources", "alsa")
+		devices = append(devices, parseFFmpegSources(out, "audio")...)
+		return devices, nil
+	}
+	return nil, fmt.Errorf("device listing is not supported on %s", runtime.GOOS)
+}
+
+// ffmpegStderr runs ffmpeg and returns what it logged. Device listing always
+// "fails" (there is no real input), so the exit status is ignored.
+func ffmpegStderr(cfg Config, args ...string) string {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(jobContext(cfg), cfg.FfmpegBin, append([]string{"-hide_banner"}, args...)...)
+	cmd.Stderr = &stderr
+	cmd.Run()
+	return stderr.String()
+}
+
+func ffmpegStdout(cfg Config, args ...string) string {
+	out, _ := exec.CommandContext(jobContext(cfg), cfg.FfmpegBin, append([]string{"-hide_banner"}, args...)...).Output()
+	return string(out)
+}
+
+var avfDevicePattern = regexp.MustCompile(`\]\s*\[(\d+)\]\s*(.+)$`)
+
+// parseAVFoundationDevices reads lines such as
+//
+//	[AVFoundation indev @ 0x7f] AVFoundation video devices:
+//	[AVFoundation indev @ 0x7f] [0] FaceTime HD Camera
+func parseAVFoundationDevices(out string) []captureDevice {
+	var devices []captureDevice
+	kind := ""
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.Contains(line, "video devices:"):
+			kind = "video"
+		case strings.Contains(line, "audio devices:"):
+			kind = "audio"
+		default:
+			if m := avfDevicePattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil && kind != "" {
+				devices = append(devices, captureDevice{Kind: kind, ID: m[1], Name: m[2]})
+			}
+		}
+	}
+	return devices
+}
+
+var dshowDevicePattern = regexp.MustCompile(`"([^"]+)"(?:\s*\((video|audio)\))?`)
+
+// parseDShowDevices handles both the current format
+//
+//	[dshow @ 000001] "Integrated Camera" (video)
+//
+// and older builds that group devices under "DirectShow video devices".
+// Alternative-name lines ("@device_pnp_...") are skipped.
+func parseDShowDevices(out string) []captureDevice {
+	var devices []captureDevice
+	kind := ""
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.Contains(line, "DirectShow video devices"):
+			kind = "video"
+			continue
+		case strings.Contains(line, "DirectShow audio devices"):
+			kind = "audio"
+			continue
+		case strings.Contains(line, "Alternative name"):
+			continue
+		}
+		m := dshowDevicePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		k := m[2]
+		if k == "" {
+			k = kind
+		}
+		if k != "" {
+			devices = append(devices, captureDevice{Kind: k, ID: m[1], Name: m[1]})
+		}
+	}
+	return devices
+}
+
+// parseFFmpegSources reads `ffmpeg -sources` output, where each device is
+// an indented "id [description]" line.
+func parseFFmpegSources(out, kind string) []captureDevice {
+	var devices []captureDevice
+	for _, line := range strings.Split(out, "\n") {
+		// "*" marks the default device
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "*") {
+			continue
+		}
+		line = strings.TrimPrefix(strings.TrimSpace(line), "* ")
+		id, name, _ := strings.Cut(line, " ")
+		name = strings.Trim(strings.TrimSpace(name), "[]")
+		if name == "" {
+			name = id
+		}
+		devices = append(devices, captureDevice{Kind: kind, ID: id, Name: name})
+	}
+	return devices
+}
+
+func printCaptureDevices(cfg Config) {
+	devices, err := listCaptureDevices(cfg)
+	if err != nil {
+		errorf("Error: %v\n", err)
+		return
+	}
+	if len(devices) == 0 {
+		fmt.Println("No capture devices found.")
+		return
+	}
+	for _, kind := range []string{"video", "audio"} {
+		fmt.Printf("%s devices:\n", strings.ToUpper(kind[:1])+kind[1:])
+		for _, d := range devices {
+			if d.Kind != kind {
+				continue
+			}
+			if d.ID == d.Name {
+				fmt.Printf("  %s\n", d.Name)
+			} else {
+				fmt.Printf("  %-12s %s\n", d.ID, d.Name)
+			}
+		}
+	}
+}
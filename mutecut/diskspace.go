@@ -0,0 +1,55 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// diskSpaceMargin pads estimates, since an encode can come out larger than its source.
+const diskSpaceMargin = 1.2
+
+// estimateOutputSize guesses how many bytes the render will need: the input
+// size, scaled down to the trimmed portion when the duration is known.
+func estimateOutputSize(cfg Config) (int64, error) {
+	info, err := os.Stat(cfg.InputFile)
+	if err != nil {
+		return 0, err
+	}
+	size := float64(info.Size())
+
+	if cfg.StartTime != "" || cfg.EndTime != "" {
+		if total, err := GetDuration(cfg); err == nil && total > 0 {
+			start, end := 0.0, total
+			if cfg.StartTime != "" {
+				start = toSeconds(cfg.StartTime)
+			}
+			if cfg.EndTime != "" {
+				end = toSeconds(cfg.EndTime)
+			}
+			if end > start && end-start < total {
+				size *= (end - start) / total
+			}
+		}
+	}
+	return int64(size * diskSpaceMargin), nil
+}
+
+// ensureDiskSpace fails early when the volume holding path has less than
+// needed bytes free, instead of letting ffmpeg die near the end of a write.
+func ensureDiskSpace(path string, needed int64) error {
+	dir := filepath.Dir(path)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read free space for %s: %w", dir, err)
+	}
+	if uint64(needed) > free {
+		return fmt.Errorf("not enough disk space in %s: need ~%.1f MB, %.1f MB free",
+			dir, float64(needed)/(1024*1024), float64(free)/(1024*1024))
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !freebsd && !linux && !windows
+
+package mutecut
+
+import "errors"
+
+func freeDiskSpace(dir string) (uint64, error) {
+	return 0, errors.New("free space check is not supported on this platform")
+}
@@ -0,0 +1,93 @@
+package mutecut
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DuckRange lowers the audio to Level (1 = unchanged) during Segment.
+type DuckRange struct {
+	Segment
+	Level float64
+}
+
+// parseDuckingFile reads a ducking automation file: one "start end level"
+// range per line, e.g. "00:01:10 00:01:45 0.3". Blank lines and lines
+// starting with # are ignored.
+func parseDuckingFile(path string) ([]DuckRange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ranges []DuckRange
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected 'start end level'", path, lineNo)
+		}
+		start, err := ParseTimeToSeconds(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		end, err := ParseTimeToSeconds(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		level, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || level < 0 {
+			return nil, fmt.Errorf("%s:%d: invalid level '%s'", path, lineNo, fields[2])
+		}
+		if end <= start {
+			return nil, fmt.Errorf("%s:%d: end must be after start", path, lineNo)
+		}
+		ranges = append(ranges, DuckRange{Segment{start, end}, level})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// windowDuckRanges moves ranges onto the output's timeline like
+// windowSegments, keeping each one's level.
+func windowDuckRanges(cfg Config, ranges []DuckRange) []DuckRange {
+	var out []DuckRange
+	for _, r := range ranges {
+		for _, seg := range windowSegments(cfg, []Segment{r.Segment}) {
+			out = append(out, DuckRange{Segment: seg, Level: r.Level})
+		}
+	}
+	return out
+}
+
+// duckingFilter compiles the ranges into a single volume filter whose level
+// is re-evaluated every frame: a nested if() picks the level of the range
+// the current time falls in, and 1 outside of all of them.
+func duckingFilter(ranges []DuckRange) string {
+	expr := "1"
+	for i := len(ranges) - 1; i >= 0; i-- {
+		r := ranges[i]
+		expr = fmt.Sprintf("if(between(t,%.3f,%.3f),%g,%s)", r.Start, r.End, r.Level, expr)
+	}
+	return fmt.Sprintf("volume='%s':eval=frame", expr)
+}
+
+// sidechainDuckGraph ducks the audio labelled in whenever the voice input
+// is speaking, then mixes the voice on top. The output is left unlabelled.
+func sidechainDuckGraph(in string, voiceInput int) string {
+	return fmt.Sprintf(
+		"[%d:a]asplit=2[voice_key][voice];"+
+			"%s[voice_key]sidechaincompress=threshold=0.03:ratio=8:attack=20:release=400[bed];"+
+			"[bed][voice]amix=inputs=2:duration=first:dropout_transition=0",
+		voiceInput, in)
+}
@@ -0,0 +1,138 @@
+package mutecut
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// hasEncoder reports whether the resolved ffmpeg build ships the named encoder.
+func hasEncoder(cfg Config, name string) bool {
+	out, err := exec.CommandContext(jobContext(cfg), cfg.FfmpegBin, "-hide_banner", "-encoders").Output()
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// Lines look like: " V....D libx264    libx264 H.264 / AVC ..."
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// x264Presets lists the encoder presets from fastest to smallest output.
+// x265 uses the same names.
+var x264Presets = []string{
+	"ultrafast", "superfast", "veryfast", "faster", "fast",
+	"medium", "slow", "slower", "veryslow", "placebo",
+}
+
+// validatePreset catches a mistyped -preset before ffmpeg gets far enough
+// into the encode to reject it.
+func validatePreset(preset string) error {
+	for _, p := range x264Presets {
+		if preset == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown -preset %q; valid presets are: %s", preset, strings.Join(x264Presets, ", "))
+}
+
+// maxCRF is the top of the encoder's CRF scale: 51 for x264/x265, 63 for
+// libvpx (0 is the best quality on both).
+func maxCRF(codec string) int {
+	if codec == "libvpx-vp9" {
+		return 63
+	}
+	return 51
+}
+
+func validateCRF(codec string, crf int) error {
+	if top := maxCRF(codec); crf < 0 || crf > top {
+		return fmt.Errorf("-crf must be between 0 and %d, got %d", top, crf)
+	}
+	return nil
+}
+
+// videoReencodeFlags names the options in use that need the video decoded
+// and re-encoded, so -vcodec copy can't honour them.
+func videoReencodeFlags(cfg Config) []string {
+	checks := []struct {
+		set  bool
+		name string
+	}{
+		{cfg.Crop != "", "-crop"},
+		{cfg.ScaleFilter != "", "-scale"},
+		{cfg.Resolution != "", "-resolution"},
+		{cfg.SquareSize > 0, "-square"},
+		{cfg.Subs != "" && cfg.SubsMode == "burn", "-subs-mode burn"},
+		{cfg.Watermark != "", "-watermark"},
+		{cfg.ReplaceWith != "", "-replace-with"},
+		{cfg.IntroSlate != "" || cfg.SlateImage != "", "-intro-slate/-slate-image"},
+		{cfg.RemoveStart != "", "-remove-start/-remove-end"},
+		{cfg.TrimSilence, "-trim-silence"},
+		{cfg.Speed != 1, "-speed"},
+		{cfg.FadeIn > 0 || cfg.FadeOut > 0, "-fade-in/-fade-out"},
+		{cfg.MaxFileSize > 0, "-max-size"},
+		{cfg.BitDepth == 10, "-bitdepth 10"},
+	}
+	var names []string
+	for _, c := range checks {
+		if c.set {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// audioReencodeFlags is videoReencodeFlags for -acodec copy.
+func audioReencodeFlags(cfg Config) []string {
+	checks := []struct {
+		set  bool
+		name string
+	}{
+		{cfg.MuteStart != "" || len(cfg.MuteSegments) > 0 || cfg.AutoMute, "muting"},
+		{cfg.VolStart != "", "-vol-start/-vol-end"},
+		{cfg.ReplaceWith != "", "-replace-with"},
+		{len(cfg.MixTracks) > 0, "-mix-audio"},
+		{len(cfg.DuckRanges) > 0 || cfg.DuckVoice != "", "-ducking-file/-ducking-voice"},
+		{cfg.PeakGain != 0, "-peak-normalize"},
+		{cfg.Normalize, "-normalize"},
+		{cfg.EnhanceSpeech, "-enhance-speech"},
+		{cfg.IntroSlate != "" || cfg.SlateImage != "", "-intro-slate/-slate-image"},
+		{cfg.RemoveStart != "", "-remove-start/-remove-end"},
+		{cfg.Speed != 1, "-speed"},
+	}
+	var names []string
+	for _, c := range checks {
+		if c.set {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// validateCodecs checks -vcodec/-acodec exist in the ffmpeg build and that
+// "copy" isn't asked of a stream the other options have to re-encode.
+func validateCodecs(cfg Config) error {
+	for _, c := range []struct {
+		flag, codec string
+		reencode    []string
+	}{
+		{"-vcodec", cfg.VideoCodec, videoReencodeFlags(cfg)},
+		{"-acodec", cfg.AudioCodec, audioReencodeFlags(cfg)},
+	} {
+		switch {
+		case c.codec == "":
+		case c.codec == "copy":
+			if len(c.reencode) > 0 {
+				return fmt.Errorf("%s copy can't be combined with %s", c.flag, strings.Join(c.reencode, ", "))
+			}
+		case !hasEncoder(cfg, c.codec):
+			return fmt.Errorf("%s %s: ffmpeg has no such encoder (see 'ffmpeg -encoders')", c.flag, c.codec)
+		}
+	}
+	return nil
+}
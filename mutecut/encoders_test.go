@@ -0,0 +1,27 @@
+package mutecut
+
+import "testing"
+
+func TestValidateCRF(t *testing.T) {
+	tests := []struct {
+		codec   string
+		crf     int
+		wantErr bool
+	}{
+		{"", -1, true},
+		{"", 0, false},
+		{"", 23, false},
+		{"", 51, false},
+		{"libx265", 52, true},
+		{"", 99, true},
+		{"libvpx-vp9", 31, false},
+		{"libvpx-vp9", 63, false},
+		{"libvpx-vp9", 64, true},
+	}
+	for _, tt := range tests {
+		err := validateCRF(tt.codec, tt.crf)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateCRF(%q, %d) error = %v, wantErr %v", tt.codec, tt.crf, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// argExplanations describes the ffmpeg options this tool generates and,
+// where it applies, which of our flags controls them.
+var argExplanations = map[string]string{
+	"-ss":             "start reading the input at this time (set via -start)",
+	"-to":             "stop reading the input at this time (set via -end)",
+	"-t":              "only process this many seconds",
+	"-i":              "input to read",
+	"-f":              "force the input/output format (lavfi = generated source)",
+	"-loop":           "repeat a still image so it can be used as video",
+	"-itsoffset":      "shift the timestamps of the next input (set via -audio-delay/-auto-sync)",
+	"-map":            "pick which stream goes into the output",
+	"-filter_complex": "filter graph combining several inputs",
+	"-lavfi":          "filter graph combining several inputs",
+	"-vf":             "video filter chain",
+	"-af":             "audio filter chain (mute, speech enhancement, ...)",
+	"-c:v":            "video encoder",
+	"-preset":         "encoder speed vs. compression trade-off (set via -preset)",
+	"-crf":            "quality level, lower is better (set via -crf)",
+	"-c:a":            "audio encoder",
+	"-acodec":         "audio encoder",
+	"-b:a":            "audio bitrate",
+	"-q:a":            "variable audio quality, 0 is best and 9 is worst",
+	"-quality":        "WebP quality (set via -webp-quality)",
+	"-vn":             "drop the video",
+	"-an":             "drop the audio",
+	"-metadata":       "set a metadata tag",
+	"-map_metadata":   "where to copy metadata from (-1 = nowhere, set via -strip-metadata)",
+	"-map_chapters":   "where to copy chapters from (-1 = nowhere)",
+	"-fflags":         "muxer flags (+bitexact = no version tags)",
+	"-flags:v":        "video encoder flags",
+	"-flags:a":        "audio encoder flags",
+	"-loglevel":       "how much ffmpeg prints",
+	"-hide_banner":    "don't print the ffmpeg banner",
+	"-nostats":        "don't print encoding statistics",
+	"-y":              "overwrite the output if it exists",
+}
+
+// valuelessArgs are the ffmpeg options that don't consume the next argument.
+var valuelessArgs = map[string]bool{
+	"-y": true, "-vn": true, "-an": true, "-hide_banner": true, "-nostats": true,
+}
+
+// explainArgs prints the ffmpeg command one option per line, each annotated
+// with what it does.
+func explainArgs(bin string, args []string) {
+	fmt.Println("ffmpeg command explained:")
+	fmt.Printf("  %s\n", quoteArg(bin))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i == len(args)-1 {
+			fmt.Printf("    %-40s  output file\n", quoteArg(arg))
+			break
+		}
+
+		text := quoteArg(arg)
+		if strings.HasPrefix(arg, "-") && !valuelessArgs[arg] && i+1 < len(args)-1 {
+			i++
+			text += " " + quoteArg(args[i])
+		}
+
+		desc, ok := argExplanations[arg]
+		if !ok {
+			desc = "passed through as-is"
+		}
+		fmt.Printf("    %-40s  %s\n", text, desc)
+	}
+}
+
+// shellCommand joins bin and args into a line that can be pasted into a
+// shell as-is.
+func shellCommand(bin string, args []string) string {
+	parts := []string{quoteArg(bin)}
+	for _, arg := range args {
+		parts = append(parts, quoteArg(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteArg single-quotes an argument if a shell would otherwise split or
+// interpret it.
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`;&|<>()[]*?!{}=,") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,61 @@
+package mutecut
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// renderExtraOutputs produces the -also-mp3/-also-gif companions. They are
+// made from the finished primary output rather than the source, so they
+// share its trim and mute without another pass over the full input.
+func renderExtraOutputs(cfg Config) ([]string, error) {
+	src := cfg
+	src.InputFile = cfg.OutputFile
+	src.StartTime = ""
+	src.EndTime = ""
+	src.Duration = ""
+	src.AudioMap = ""
+	src.PeakGain = 0 // Already applied to the output; again would clip
+
+	base := strings.TrimSuffix(cfg.OutputFile, filepath.Ext(cfg.OutputFile))
+	var outputs []string
+
+	if cfg.AlsoMP3 {
+		src.Stage++
+		mp3Cfg := src
+		mp3Cfg.OutputFile = uniqueOutput(cfg, base+".mp3")
+		mp3Cfg.AudioFormat = "mp3"
+		if err := extractAudio(mp3Cfg); err != nil {
+			return outputs, err
+		}
+		outputs = append(outputs, mp3Cfg.OutputFile)
+	}
+	if cfg.AlsoGIF {
+		src.Stage++
+		gifCfg := src
+		gifCfg.OutputFile = uniqueOutput(cfg, base+".gif")
+		if err := exportGIF(gifCfg); err != nil {
+			return outputs, err
+		}
+		outputs = append(outputs, gifCfg.OutputFile)
+	}
+	return outputs, nil
+}
+
+// countStages returns how many ffmpeg runs the job makes, for progress.
+func countStages(cfg Config) int {
+	if cfg.ExtractMP3 || cfg.ExportWebP {
+		return 1
+	}
+	if cfg.ExportGIF {
+		return gifStages
+	}
+	stages := 1
+	if cfg.AlsoMP3 {
+		stages++
+	}
+	if cfg.AlsoGIF {
+		stages += gifStages
+	}
+	return stages
+}
@@ -0,0 +1,36 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fadeFilter fades the picture in from black over the first in seconds and
+// out to black over the last out seconds of an output duration long.
+func fadeFilter(in, out, duration float64) string {
+	var fades []string
+	if in > 0 {
+		fades = append(fades, fmt.Sprintf("fade=t=in:st=0:d=%.3f", in))
+	}
+	if out > 0 {
+		fades = append(fades, fmt.Sprintf("fade=t=out:st=%.3f:d=%.3f", max(duration-out, 0), out))
+	}
+	return strings.Join(fades, ",")
+}
+
+func validateFade(cfg Config) error {
+	if cfg.FadeIn < 0 || cfg.FadeOut < 0 {
+		return fmt.Errorf("-fade-in and -fade-out cannot be negative")
+	}
+	if cfg.FadeOut == 0 && cfg.FadeIn == 0 {
+		return nil
+	}
+	duration := expectedOutputDuration(cfg)
+	if cfg.FadeOut > 0 && duration <= 0 {
+		return fmt.Errorf("-fade-out needs to know the output length; set -end or -duration")
+	}
+	if duration > 0 && cfg.FadeIn+cfg.FadeOut > duration {
+		return fmt.Errorf("-fade-in and -fade-out (%.3fs together) are longer than the %.3fs output", cfg.FadeIn+cfg.FadeOut, duration)
+	}
+	return nil
+}
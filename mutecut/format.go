@@ -0,0 +1,73 @@
+package mutecut
+
+import "fmt"
+
+// outputContainer is a container -format can write, with the codecs it
+// needs. An empty VideoCodec keeps the usual H.264/HEVC choice.
+type outputContainer struct {
+	Ext        string
+	VideoCodec string
+	AudioCodec string
+	CRF        int // Default CRF on the codec's own scale, 0 = keep -crf's
+}
+
+var outputContainers = map[string]outputContainer{
+	"mp4":  {".mp4", "", "aac", 0},
+	"mkv":  {".mkv", "", "aac", 0},
+	"mov":  {".mov", "", "aac", 0},
+	"webm": {".webm", "libvpx-vp9", "libopus", 31},
+}
+
+// applyFormat switches the output to the -format container: the file
+// extension changes and, for WebM, the codecs become VP9 and Opus. An
+// explicit -crf still wins.
+func applyFormat(cfg *Config, explicit map[string]bool) error {
+	container, ok := outputContainers[cfg.Format]
+	if !ok {
+		return fmt.Errorf("invalid -format '%s' (use mp4, mkv, webm or mov)", cfg.Format)
+	}
+	if cfg.ExtractMP3 || cfg.ExportWebP || cfg.ExportGIF {
+		return fmt.Errorf("-format sets the video container; use -audio-format, -webp or -gif on their own")
+	}
+	if container.VideoCodec != "" {
+		if cfg.BitDepth == 10 || (cfg.VideoCodec != "" && cfg.VideoCodec != container.VideoCodec) {
+			return fmt.Errorf("-format %s always encodes %s; drop -bitdepth/-quality", cfg.Format, container.VideoCodec)
+		}
+		cfg.VideoCodec = container.VideoCodec
+		if !explicit["crf"] && container.CRF > 0 {
+			cfg.CRF = container.CRF
+		}
+	}
+	cfg.OutputFile = replaceExt(cfg.OutputFile, container.Ext)
+	return nil
+}
+
+// audioEncodeArgs is the audio encoding for a video output: -acodec if
+// given, otherwise AAC unless -format needs something else.
+func audioEncodeArgs(cfg Config) []string {
+	codec := "aac"
+	if container, ok := outputContainers[cfg.Format]; ok {
+		codec = container.AudioCodec
+	}
+	if cfg.AudioCodec != "" {
+		codec = cfg.AudioCodec
+	}
+	if codec == "copy" {
+		return []string{"-c:a", "copy"}
+	}
+	return []string{"-c:a", codec, "-b:a", "192k"}
+}
+
+// vp9CodecArgs are the encoder options for WebM output. libvpx has no
+// x264-style presets; constant quality needs -b:v 0 alongside -crf.
+func vp9CodecArgs(cfg Config) []string {
+	args := []string{"-c:v", "libvpx-vp9", "-row-mt", "1", "-pix_fmt", "yuv420p"}
+	if cfg.VideoBitrate > 0 {
+		args = append(args, "-b:v", fmt.Sprintf("%dk", cfg.VideoBitrate))
+		if cfg.Pass > 0 {
+			args = append(args, "-pass", fmt.Sprint(cfg.Pass), "-passlogfile", cfg.PassLog)
+		}
+		return args
+	}
+	return append(args, "-crf", fmt.Sprint(cfg.CRF), "-b:v", "0")
+}
@@ -0,0 +1,43 @@
+package mutecut
+
+import "fmt"
+
+// applyFrameTrim turns -start-frame/-end-frame (0-based, end inclusive) into
+// -start/-end timestamps using the input's frame rate. A negative frame
+// means the flag wasn't given.
+func applyFrameTrim(cfg *Config, startFrame, endFrame int) error {
+	if startFrame < 0 && endFrame < 0 {
+		return nil
+	}
+	if (startFrame >= 0 && cfg.StartTime != "") || (endFrame >= 0 && cfg.EndTime != "") {
+		return fmt.Errorf("use either frame numbers or -start/-end times, not both")
+	}
+
+	_, _, fps, err := probeVideoGeometry(*cfg)
+	if err != nil {
+		return err
+	}
+	duration, err := GetDuration(*cfg)
+	if err != nil {
+		return err
+	}
+	total := int(duration*fps + 0.5)
+
+	if startFrame >= total || endFrame >= total {
+		return fmt.Errorf("frame out of range: input has %d frames (0-%d)", total, total-1)
+	}
+	if startFrame >= 0 && endFrame >= 0 && endFrame < startFrame {
+		return fmt.Errorf("-end-frame %d is before -start-frame %d", endFrame, startFrame)
+	}
+
+	if startFrame >= 0 {
+		cfg.StartTime = fmt.Sprintf("%.6f", float64(startFrame)/fps)
+		logf("Start frame %d -> %s\n", startFrame, formatTimestamp(float64(startFrame)/fps))
+	}
+	if endFrame >= 0 {
+		// Run to the end of the last kept frame
+		cfg.EndTime = fmt.Sprintf("%.6f", float64(endFrame+1)/fps)
+		logf("End frame %d -> %s\n", endFrame, formatTimestamp(float64(endFrame+1)/fps))
+	}
+	return nil
+}
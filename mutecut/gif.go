@@ -0,0 +1,63 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultGifFPS   = 10
+	defaultGifWidth = 480
+	// Palette generation plus the render itself
+	gifStages = 2
+)
+
+// exportGIF renders the (trimmed) input as a GIF using the two-step
+// palettegen/paletteuse approach, which looks far better than ffmpeg's
+// default 256-colour dithering.
+func exportGIF(cfg Config) error {
+	outputFile := cfg.OutputFile
+	if !strings.HasSuffix(strings.ToLower(outputFile), ".gif") {
+		outputFile = strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".gif"
+	}
+	cfg.OutputFile = outputFile
+
+	logf("Exporting GIF to: %s\n", cfg.OutputFile)
+
+	palette, err := os.CreateTemp("", "mutecut-palette-*.png")
+	if err != nil {
+		return fmt.Errorf("creating palette file: %w", err)
+	}
+	palette.Close()
+	defer os.Remove(palette.Name())
+
+	filters := fmt.Sprintf("fps=%d,scale=%d:-1:flags=lanczos", cfg.GifFPS, cfg.GifWidth)
+	inputArgs := getInputArgs(cfg)
+
+	// Pass 1: build an optimised palette for this clip
+	args := append(inputArgs, "-vf", filters+",palettegen", "-y", palette.Name())
+	if err := runFFmpeg(cfg, args); err != nil {
+		return err
+	}
+
+	// Pass 2: render using that palette
+	cfg.Stage++
+	args = append(getInputArgs(cfg),
+		"-i", palette.Name(),
+		"-lavfi", filters+" [x]; [x][1:v] paletteuse",
+		"-y", cfg.OutputFile,
+	)
+	return runFFmpeg(cfg, args)
+}
+
+func validateGIF(cfg Config) error {
+	if cfg.GifFPS <= 0 {
+		return fmt.Errorf("-gif-fps must be greater than 0")
+	}
+	if cfg.GifWidth <= 0 {
+		return fmt.Errorf("-gif-width must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package mutecut
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// hashFile returns the hex SHA-256 of the file at path, streaming it so
+// large outputs aren't read into memory.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeHashSidecar writes "<hash>  <name>" to path.sha256, the format
+// `sha256sum -c` checks.
+func writeHashSidecar(path, hash string) error {
+	line := fmt.Sprintf("%s  %s\n", hash, filepath.Base(path))
+	return os.WriteFile(path+".sha256", []byte(line), 0644)
+}
+
+// hashOutputs hashes each output, optionally writing a sidecar next to it,
+// and returns the hashes by path.
+func hashOutputs(outputs []string, sidecar bool) (map[string]string, error) {
+	hashes := make(map[string]string, len(outputs))
+	for _, out := range outputs {
+		hash, err := hashFile(out)
+		if err != nil {
+			return hashes, fmt.Errorf("cannot hash %s: %w", out, err)
+		}
+		hashes[out] = hash
+		if sidecar {
+			if err := writeHashSidecar(out, hash); err != nil {
+				return hashes, err
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// writeHashManifest writes hashes to dir/SHA256SUMS in `sha256sum -c`
+// format, with paths relative to dir, and returns the manifest's path.
+func writeHashManifest(dir string, hashes map[string]string) (string, error) {
+	var b strings.Builder
+	for _, path := range slices.Sorted(maps.Keys(hashes)) {
+		name := path
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		fmt.Fprintf(&b, "%s  %s\n", hashes[path], filepath.ToSlash(name))
+	}
+	manifest := filepath.Join(dir, "SHA256SUMS")
+	return manifest, os.WriteFile(manifest, []byte(b.String()), 0644)
+}
@@ -0,0 +1,27 @@
+package mutecut
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteHashManifest(t *testing.T) {
+	dir := t.TempDir()
+	hashes := map[string]string{
+		filepath.Join(dir, "b.mp4"):        "bbbb",
+		filepath.Join(dir, "sub", "a.mp4"): "aaaa",
+	}
+	manifest, err := writeHashManifest(dir, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "bbbb  b.mp4\naaaa  sub/a.mp4\n"
+	if string(got) != want {
+		t.Errorf("manifest = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package mutecut
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// hdrMetadata is the colour description of the source's video stream, kept
+// so a 10-bit encode can carry it through unchanged.
+type hdrMetadata struct {
+	Primaries string
+	Transfer  string
+	Space     string
+
+	// x265 "master-display" and "max-cll" values, empty if the source has
+	// no mastering metadata.
+	MasterDisplay string
+	MaxCLL        string
+}
+
+// probeHDRMetadata reads the first video stream's colour tags and, from its
+// first frame, any HDR10 mastering display / content light level data.
+func probeHDRMetadata(cfg Config) (hdrMetadata, error) {
+	var meta hdrMetadata
+	streams, err := probeStreams(cfg)
+	if err != nil {
+		return meta, err
+	}
+	for _, s := range streams {
+		if s.CodecType == "video" {
+			meta.Primaries, meta.Transfer, meta.Space = s.ColorPrimaries, s.ColorTransfer, s.ColorSpace
+			break
+		}
+	}
+
+	out, err := runProbe(cfg,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", "%+#1",
+		"-show_entries", "frame=side_data_list",
+		"-of", "json",
+	)
+	if err != nil {
+		return meta, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	var result struct {
+		Frames []struct {
+			SideData []map[string]any `json:"side_data_list"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return meta, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	for _, frame := range result.Frames {
+		for _, sd := range frame.SideData {
+			switch sd["side_data_type"] {
+			case "Mastering display metadata":
+				meta.MasterDisplay = masterDisplayParam(sd)
+			case "Content light level metadata":
+				meta.MaxCLL = fmt.Sprintf("%v,%v", sd["max_content"], sd["max_average"])
+			}
+		}
+	}
+	return meta, nil
+}
+
+// masterDisplayParam converts ffprobe's rational chromaticities and
+// luminances into x265's G(x,y)B(x,y)R(x,y)WP(x,y)L(max,min) form, which
+// counts in units of 0.00002 and 0.0001 cd/m² respectively.
+func masterDisplayParam(sd map[string]any) string {
+	chroma := func(key string) int64 { return scaleRational(sd[key], 50000) }
+	lum := func(key string) int64 { return scaleRational(sd[key], 10000) }
+	return fmt.Sprintf("G(%d,%d)B(%d,%d)R(%d,%d)WP(%d,%d)L(%d,%d)",
+		chroma("green_x"), chroma("green_y"),
+		chroma("blue_x"), chroma("blue_y"),
+		chroma("red_x"), chroma("red_y"),
+		chroma("white_point_x"), chroma("white_point_y"),
+		lum("max_luminance"), lum("min_luminance"))
+}
+
+func scaleRational(v any, scale int64) int64 {
+	s, _ := v.(string)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0
+	}
+	r.Mul(r, big.NewRat(scale, 1))
+	f, _ := r.Float64()
+	return int64(f + 0.5)
+}
+
+// videoCodecArgs returns the encoder arguments for the main video stream:
+// libx264 (or the -quality profile's codec) for the usual 8-bit output, or a
+// 10-bit libx265 encode tagged with the source's colour metadata when
+// -bitdepth 10 is set. Rate control is CRF unless a target bitrate was
+// worked out for -max-size. A -hwaccel encoder replaces all of this.
+func videoCodecArgs(cfg Config) []string {
+	if cfg.HWEncoder != "" {
+		return hwCodecArgs(cfg)
+	}
+	switch cfg.VideoCodec {
+	case "copy":
+		return []string{"-c:v", "copy"}
+	case "libvpx-vp9":
+		return vp9CodecArgs(cfg)
+	case "", "libx264", "libx265":
+	default:
+		// Any other -vcodec: its own defaults, as x264's -preset/-crf don't apply
+		args := []string{"-c:v", cfg.VideoCodec}
+		if cfg.VideoBitrate > 0 {
+			args = append(args, "-b:v", fmt.Sprintf("%dk", cfg.VideoBitrate))
+		}
+		return args
+	}
+	codec, pixFmt := "libx264", ""
+	var params []string
+	if cfg.BitDepth == 10 {
+		codec, pixFmt = "libx265", "yuv420p10le"
+		params = append(params, "repeat-headers=1")
+		if cfg.HDR.MasterDisplay != "" {
+			params = append(params, "hdr10=1", "master-display="+cfg.HDR.MasterDisplay)
+			if cfg.HDR.MaxCLL != "" {
+				params = append(params, "max-cll="+cfg.HDR.MaxCLL)
+			}
+		}
+	} else if cfg.VideoCodec != "" {
+		codec, pixFmt = cfg.VideoCodec, "yuv420p"
+	}
+
+	args := []string{"-c:v", codec, "-preset", cfg.Preset}
+	if cfg.VideoBitrate > 0 {
+		args = append(args, "-b:v", fmt.Sprintf("%dk", cfg.VideoBitrate))
+		if cfg.Pass > 0 {
+			// libx265 takes its two-pass settings through x265-params
+			if codec == "libx265" {
+				params = append(params, fmt.Sprintf("pass=%d", cfg.Pass), "stats="+cfg.PassLog+".log")
+			} else {
+				args = append(args, "-pass", strconv.Itoa(cfg.Pass), "-passlogfile", cfg.PassLog)
+			}
+		}
+	} else {
+		args = append(args, "-crf", strconv.Itoa(cfg.CRF))
+	}
+
+	if pixFmt != "" {
+		args = append(args, "-pix_fmt", pixFmt)
+	}
+	if codec == "libx265" {
+		args = append(args, "-tag:v", "hvc1") // So Apple players accept HEVC in MP4
+	}
+	if cfg.BitDepth == 10 {
+		if cfg.HDR.Primaries != "" {
+			args = append(args, "-color_primaries", cfg.HDR.Primaries)
+		}
+		if cfg.HDR.Transfer != "" {
+			args = append(args, "-color_trc", cfg.HDR.Transfer)
+		}
+		if cfg.HDR.Space != "" {
+			args = append(args, "-colorspace", cfg.HDR.Space)
+		}
+	}
+	if len(params) > 0 {
+		args = append(args, "-x265-params", strings.Join(params, ":"))
+	}
+	return args
+}
+
+func validateBitDepth(cfg Config) error {
+	switch cfg.BitDepth {
+	case 8:
+		return nil
+	case 10:
+		if !hasEncoder(cfg, "libx265") {
+			return fmt.Errorf("-bitdepth 10 needs an ffmpeg build with libx265")
+		}
+		return nil
+	}
+	return fmt.Errorf("-bitdepth must be 8 or 10")
+}
@@ -0,0 +1,79 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// hwEncoders maps -hwaccel names to their H.264 encoders.
+var hwEncoders = map[string]string{
+	"nvenc": "h264_nvenc",
+	"qsv":   "h264_qsv",
+	"vaapi": "h264_vaapi",
+}
+
+// vaapiDevice is the render node VAAPI encodes go through.
+const vaapiDevice = "/dev/dri/renderD128"
+
+// vaapiUpload moves filtered frames into GPU memory for h264_vaapi.
+const vaapiUpload = "format=nv12,hwupload"
+
+// nvencPresets maps the x264 preset names onto NVENC's p1 (fastest) to p7
+// (best quality).
+var nvencPresets = map[string]string{
+	"ultrafast": "p1", "superfast": "p1", "veryfast": "p2", "faster": "p3", "fast": "p3",
+	"medium": "p4", "slow": "p5", "slower": "p6", "veryslow": "p7", "placebo": "p7",
+}
+
+// resolveHWAccel picks the encoder for -hwaccel. If this ffmpeg build lacks
+// it the encode falls back to libx264 with a warning instead of failing.
+func resolveHWAccel(cfg Config, name string) (string, error) {
+	if name == "" || name == "none" {
+		return "", nil
+	}
+	encoder, ok := hwEncoders[name]
+	if !ok {
+		return "", fmt.Errorf("unknown -hwaccel '%s' (one of: nvenc, qsv, vaapi, none)", name)
+	}
+	if !hasEncoder(cfg, encoder) {
+		warnf("Warning: this ffmpeg build has no %s encoder, falling back to libx264.\n", encoder)
+		return "", nil
+	}
+	return encoder, nil
+}
+
+// hwCodecArgs is videoCodecArgs for the hardware encoders. Each takes its
+// constant-quality target under a different option (-cq, -global_quality,
+// -qp); the CRF value carries over closely enough.
+func hwCodecArgs(cfg Config) []string {
+	args := []string{"-c:v", cfg.HWEncoder}
+	quality := strconv.Itoa(cfg.CRF)
+	switch cfg.HWEncoder {
+	case "h264_nvenc":
+		args = append(args, "-preset", nvencPresets[cfg.Preset])
+		if cfg.VideoBitrate == 0 {
+			args = append(args, "-rc", "vbr", "-cq", quality, "-b:v", "0")
+		}
+	case "h264_qsv":
+		// QSV knows the x264 names except the two extremes at each end
+		preset := cfg.Preset
+		switch preset {
+		case "ultrafast", "superfast":
+			preset = "veryfast"
+		case "placebo":
+			preset = "veryslow"
+		}
+		args = append(args, "-preset", preset)
+		if cfg.VideoBitrate == 0 {
+			args = append(args, "-global_quality", quality)
+		}
+	case "h264_vaapi":
+		if cfg.VideoBitrate == 0 {
+			args = append(args, "-qp", quality)
+		}
+	}
+	if cfg.VideoBitrate > 0 {
+		args = append(args, "-b:v", fmt.Sprintf("%dk", cfg.VideoBitrate))
+	}
+	return args
+}
@@ -0,0 +1,102 @@
+package mutecut
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// mediaInfo is the part of ffprobe's -show_format -show_streams output
+// that -info summarises.
+type mediaInfo struct {
+	Format struct {
+		Filename   string `json:"filename"`
+		FormatName string `json:"format_long_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []infoStream `json:"streams"`
+}
+
+type infoStream struct {
+	probeStream
+	SampleRate    string `json:"sample_rate"`
+	ChannelLayout string `json:"channel_layout"`
+	BitRate       string `json:"bit_rate"`
+}
+
+// printInfo prints a summary of the input's container and streams, or
+// ffprobe's JSON untouched when raw is set.
+func printInfo(cfg Config, raw bool) error {
+	out, err := runProbe(cfg, "-v", "error", "-show_format", "-show_streams", "-of", "json")
+	if err != nil {
+		return fmt.Errorf("ffprobe failed: %w", err)
+	}
+	if raw {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+
+	var info mediaInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	f := info.Format
+	fmt.Printf("File:     %s\n", f.Filename)
+	fmt.Printf("Format:   %s\n", f.FormatName)
+	if d, err := strconv.ParseFloat(f.Duration, 64); err == nil {
+		fmt.Printf("Duration: %s\n", formatTimestamp(d))
+	}
+	if size, err := strconv.ParseInt(f.Size, 10, 64); err == nil {
+		fmt.Printf("Size:     %s\n", formatSize(size))
+	}
+	if rate := formatBitRate(f.BitRate); rate != "" {
+		fmt.Printf("Bitrate:  %s\n", rate)
+	}
+	fmt.Printf("Streams:  %d\n", len(info.Streams))
+	for _, s := range info.Streams {
+		fmt.Printf("  #%d %s\n", s.Index, describeStream(s))
+	}
+	return nil
+}
+
+func describeStream(s infoStream) string {
+	parts := []string{s.CodecName}
+	switch s.CodecType {
+	case "video":
+		if s.Width > 0 {
+			parts = append(parts, fmt.Sprintf("%dx%d", s.Width, s.Height))
+		}
+		if fps := parseFrameRate(s.FrameRate); fps > 0 {
+			parts = append(parts, fmt.Sprintf("%.2f fps", fps))
+		}
+	case "audio":
+		if s.SampleRate != "" {
+			parts = append(parts, s.SampleRate+" Hz")
+		}
+		if s.ChannelLayout != "" {
+			parts = append(parts, s.ChannelLayout)
+		}
+	}
+	if rate := formatBitRate(s.BitRate); rate != "" {
+		parts = append(parts, rate)
+	}
+	desc := s.CodecType + ": " + strings.Join(parts, ", ")
+	if lang := s.Tags["language"]; lang != "" {
+		desc += " [" + lang + "]"
+	}
+	return desc
+}
+
+// formatBitRate renders ffprobe's bits-per-second string, or "" if unknown.
+func formatBitRate(bps string) string {
+	n, err := strconv.ParseInt(bps, 10, 64)
+	if err != nil || n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d kb/s", n/1000)
+}
@@ -0,0 +1,41 @@
+package mutecut
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// printKeyframes lists where the keyframes are, which is where a stream
+// copy cut can land without re-encoding.
+func printKeyframes(cfg Config, asJSON bool) error {
+	times, err := probeKeyframes(cfg)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		out := struct {
+			Input     string    `json:"input"`
+			Keyframes []float64 `json:"keyframes"`
+		}{cfg.InputFile, times}
+		if out.Keyframes == nil {
+			out.Keyframes = []float64{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	fmt.Printf("Keyframes in %s (%d):\n", cfg.InputFile, len(times))
+	for i, t := range times {
+		fmt.Printf("%5d  %s  (%.3fs)\n", i+1, formatTimestamp(t), t)
+	}
+	return nil
+}
+
+// formatTimestamp renders seconds as HH:MM:SS.mmm.
+func formatTimestamp(sec float64) string {
+	ms := int64(sec*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
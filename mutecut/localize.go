@@ -0,0 +1,78 @@
+package mutecut
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const localizeRetries = 3
+
+// localizeInput copies the input to a local temp file so ffmpeg reads from
+// fast, reliable storage instead of a network share. A failed read resumes
+// from the last good offset rather than starting over. The returned cleanup
+// removes the temp copy.
+func localizeInput(ctx context.Context, path string) (string, func(), error) {
+	tmp, err := os.CreateTemp("", "mutecut-*"+filepath.Ext(path))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+
+	logf("Copying input to local temp file: %s\n", tmp.Name())
+	start := time.Now()
+
+	var copied int64
+	for attempt := 1; ; attempt++ {
+		n, err := copyFrom(ctx, path, tmp, copied)
+		copied += n
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil || attempt == localizeRetries {
+			tmp.Close()
+			cleanup()
+			return "", nil, fmt.Errorf("failed to copy input after %d attempts: %w", attempt, err)
+		}
+		logf("Read failed at %d bytes (%v), retrying...\n", copied, err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	logf("Copied %.1f MB in %s\n", float64(copied)/(1024*1024), time.Since(start).Round(time.Millisecond))
+	return tmp.Name(), cleanup, nil
+}
+
+// copyFrom appends the source, starting at offset, to dst.
+func copyFrom(ctx context.Context, path string, dst *os.File, offset int64) (int64, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(dst, ctxReader{ctx, src})
+}
+
+// ctxReader stops a copy once ctx is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
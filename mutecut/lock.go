@@ -0,0 +1,43 @@
+package mutecut
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var errLocked = errors.New("lock is held by another process")
+
+// acquireInstanceLock takes an exclusive lock on a file in the temp dir so
+// only one heavy run happens at a time. The OS drops the lock when the
+// process exits, however it exits, so there is no stale lock to clean up.
+func acquireInstanceLock(wait bool) (*os.File, error) {
+	path := filepath.Join(os.TempDir(), "mutecut.lock")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open lock file: %w", err)
+	}
+
+	announced := false
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			return f, nil
+		}
+		if err != errLocked {
+			f.Close()
+			return nil, fmt.Errorf("cannot lock %s: %w", path, err)
+		}
+		if !wait {
+			f.Close()
+			return nil, fmt.Errorf("another instance is already running (use -single-instance-wait to queue)")
+		}
+		if !announced {
+			logln("Another instance is running. Waiting for it to finish...")
+			announced = true
+		}
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !freebsd && !linux && !windows
+
+package mutecut
+
+import "os"
+
+// Without a file locking primitive, -single-instance is a no-op.
+func tryLockFile(f *os.File) error {
+	return nil
+}
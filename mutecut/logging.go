@@ -0,0 +1,77 @@
+package mutecut
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// logLevel orders messages by importance. -v shows debug and up, the
+// default info and up, -quiet only warnings and errors.
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var (
+	minLevel      = levelInfo
+	logTimestamps bool // -log-time
+)
+
+// logAt writes one message at level, prefixed with the time if -log-time
+// is set. Leading blank lines stay ahead of the timestamp.
+func logAt(level logLevel, w io.Writer, msg string) {
+	if level < minLevel {
+		return
+	}
+	if logTimestamps {
+		body := strings.TrimLeft(msg, "\n")
+		msg = msg[:len(msg)-len(body)] + time.Now().Format("15:04:05.000 ") + body
+	}
+	io.WriteString(w, msg)
+}
+
+// debugf logs details only wanted with -v, such as full ffmpeg commands.
+func debugf(format string, args ...any) {
+	logAt(levelDebug, os.Stdout, fmt.Sprintf(format, args...))
+}
+
+// logf and logln print a status message unless -quiet is set.
+func logf(format string, args ...any) {
+	logAt(levelInfo, os.Stdout, fmt.Sprintf(format, args...))
+}
+
+func logln(args ...any) {
+	logAt(levelInfo, os.Stdout, fmt.Sprintln(args...))
+}
+
+// warnf and errorf write to stderr and survive -quiet.
+func warnf(format string, args ...any) {
+	logAt(levelWarn, os.Stderr, fmt.Sprintf(format, args...))
+}
+
+func warnln(args ...any) {
+	logAt(levelWarn, os.Stderr, fmt.Sprintln(args...))
+}
+
+func errorf(format string, args ...any) {
+	logAt(levelError, os.Stderr, fmt.Sprintf(format, args...))
+}
+
+func errorln(args ...any) {
+	logAt(levelError, os.Stderr, fmt.Sprintln(args...))
+}
+
+// progressf draws progress output: silenced by -quiet like info messages,
+// but never timestamped since it redraws a line in place.
+func progressf(format string, args ...any) {
+	if minLevel <= levelInfo {
+		fmt.Printf(format, args...)
+	}
+}
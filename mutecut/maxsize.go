@@ -0,0 +1,134 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// fallbackAudioKbps is assumed for copied audio whose bitrate the
+	// container doesn't record.
+	fallbackAudioKbps = 192
+	// muxOverhead leaves room for container overhead in the size budget.
+	muxOverhead = 0.02
+	// minVideoBitrateKbps is where a size-capped encode stops being watchable.
+	minVideoBitrateKbps = 100
+)
+
+// parseSize parses -max-size values such as "25MB", "700M", "1.5G" or a raw
+// byte count. Units are binary (1M = 1024*1024 bytes).
+func parseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(value, "B")
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		mult   float64
+	}{{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value, multiplier = strings.TrimSuffix(value, unit.suffix), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size '%s' (e.g. 25MB, 700M or a byte count)", s)
+	}
+	return int64(n * multiplier), nil
+}
+
+// targetVideoBitrate returns the video bitrate, in kb/s, that fits duration
+// seconds of output into maxBytes alongside audioKbps of audio.
+func targetVideoBitrate(maxBytes int64, duration float64, audioKbps int) int {
+	totalKbps := float64(maxBytes) * 8 * (1 - muxOverhead) / duration / 1000
+	return int(totalKbps) - audioKbps
+}
+
+// outputAudioKbps is the audio's share of the -max-size budget: the -b:a
+// the encode uses, the source's own bitrate when the audio is copied, or
+// nothing when the output has no audio.
+func outputAudioKbps(cfg Config) int {
+	var source *probeStream
+	streams, err := probeStreams(cfg)
+	for i := range streams {
+		if streams[i].CodecType == "audio" {
+			source = &streams[i]
+			break
+		}
+	}
+	if err == nil && source == nil && cfg.DuckVoice == "" {
+		return 0
+	}
+
+	args := audioEncodeArgs(cfg)
+	if args[1] == "copy" {
+		if source != nil {
+			if bps, err := strconv.Atoi(source.BitRate); err == nil {
+				return (bps + 999) / 1000
+			}
+		}
+		return fallbackAudioKbps
+	}
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-b:a" {
+			if kbps, err := strconv.Atoi(strings.TrimSuffix(args[i+1], "k")); err == nil {
+				return kbps
+			}
+		}
+	}
+	return fallbackAudioKbps
+}
+
+// twoPassCut encodes with a bitrate sized so the output lands under
+// cfg.MaxFileSize. The first pass only analyses; its log steers the second.
+func twoPassCut(cfg Config) error {
+	duration := cfg.ExpectedDuration
+	if duration <= 0 {
+		duration = expectedOutputDuration(cfg)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("cannot work out the output length needed for -max-size")
+	}
+
+	bitrate := targetVideoBitrate(cfg.MaxFileSize, duration, outputAudioKbps(cfg))
+	if bitrate < minVideoBitrateKbps {
+		warnf("Warning: -max-size leaves only %dk/s for video; expect poor quality.\n", bitrate)
+		if bitrate < 1 {
+			bitrate = 1
+		}
+	}
+	cfg.VideoBitrate = bitrate
+	if cfg.HWEncoder != "" {
+		// Hardware encoders have no x264-style stats file to feed a second pass
+		logf("Encoding at %dk video to stay under %.1f MB\n", bitrate, float64(cfg.MaxFileSize)/(1<<20))
+		return runFFmpeg(cfg, simpleCutArgs(cfg))
+	}
+	logf("Two-pass encode at %dk video to stay under %.1f MB\n", bitrate, float64(cfg.MaxFileSize)/(1<<20))
+
+	logDir, err := os.MkdirTemp("", "mutecut-2pass-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(logDir)
+
+	// Each pass takes half of this job's share of the progress bar
+	cfg.PassLog = filepath.Join(logDir, "pass")
+	stage, stages := max(cfg.Stage, 1), max(cfg.Stages, 1)
+
+	first := cfg
+	first.Pass = 1
+	first.Stage, first.Stages = 2*stage-1, 2*stages
+	args := simpleCutArgs(first)
+	args = append(args[:len(args)-1], "-f", "null", "-")
+	if err := runFFmpeg(first, args); err != nil {
+		return err
+	}
+
+	second := cfg
+	second.Pass = 2
+	second.Stage, second.Stages = 2*stage, 2*stages
+	return runFFmpeg(second, simpleCutArgs(second))
+}
@@ -0,0 +1,58 @@
+package mutecut
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeProbe returns an ffprobe stand-in that prints json for any query.
+func fakeProbe(t *testing.T, json string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ffprobe")
+	}
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	script := "#!/bin/sh\ncat <<'EOF'\n" + json + "\nEOF\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOutputAudioKbps(t *testing.T) {
+	const (
+		withRate    = `{"streams": [{"codec_type": "video"}, {"codec_type": "audio", "bit_rate": "128000"}]}`
+		withoutRate = `{"streams": [{"codec_type": "video"}, {"codec_type": "audio"}]}`
+		silent      = `{"streams": [{"codec_type": "video"}]}`
+	)
+	tests := []struct {
+		name  string
+		probe string
+		cfg   Config
+		want  int
+	}{
+		{"aac", withRate, Config{}, 192},
+		{"copy", withRate, Config{AudioCodec: "copy"}, 128},
+		{"copy, rate unknown", withoutRate, Config{AudioCodec: "copy"}, fallbackAudioKbps},
+		{"no audio", silent, Config{}, 0},
+	}
+	for _, tt := range tests {
+		cfg := tt.cfg
+		cfg.InputFile, cfg.FfprobeBin = "in.mp4", fakeProbe(t, tt.probe)
+		if got := outputAudioKbps(cfg); got != tt.want {
+			t.Errorf("%s: outputAudioKbps = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTargetVideoBitrate(t *testing.T) {
+	// 10 MB over 100s is 800 kb/s, 784 after the mux overhead
+	if got := targetVideoBitrate(10_000_000, 100, 0); got != 784 {
+		t.Errorf("targetVideoBitrate with no audio = %d, want 784", got)
+	}
+	if got := targetVideoBitrate(10_000_000, 100, 128); got != 784-128 {
+		t.Errorf("targetVideoBitrate with 128k audio = %d, want %d", got, 784-128)
+	}
+}
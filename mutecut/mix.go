@@ -0,0 +1,73 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resolveMixTracks probes the input's audio streams and fills in
+// cfg.MixTracks (all of them unless a subset was requested) and the weights.
+func resolveMixTracks(cfg *Config, tracks, weights string) error {
+	streams, err := probeStreams(*cfg)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, s := range streams {
+		if s.CodecType == "audio" {
+			count++
+		}
+	}
+
+	var selected []int
+	if tracks == "" {
+		for i := 0; i < count; i++ {
+			selected = append(selected, i)
+		}
+	} else {
+		for _, t := range strings.Split(tracks, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(t))
+			if err != nil || n < 0 || n >= count {
+				return fmt.Errorf("invalid audio track '%s' (input has %d audio tracks)", t, count)
+			}
+			selected = append(selected, n)
+		}
+	}
+	if len(selected) < 2 {
+		return fmt.Errorf("-mix-audio needs at least two audio tracks, input has %d", count)
+	}
+
+	var w []string
+	if weights != "" {
+		for _, v := range strings.Split(weights, ",") {
+			v = strings.TrimSpace(v)
+			if f, err := strconv.ParseFloat(v, 64); err != nil || f < 0 {
+				return fmt.Errorf("invalid mix weight '%s'", v)
+			}
+			w = append(w, v)
+		}
+		if len(w) != len(selected) {
+			return fmt.Errorf("got %d mix weights for %d tracks", len(w), len(selected))
+		}
+	}
+
+	cfg.MixTracks = selected
+	cfg.MixWeights = strings.Join(w, " ")
+	logf("Mixing %d audio tracks into one\n", len(selected))
+	return nil
+}
+
+// mixAudioGraph builds an amix over the given audio tracks of an input. The
+// output is left unlabelled so more filters can be chained on.
+func mixAudioGraph(input int, tracks []int, weights string) string {
+	var b strings.Builder
+	for _, t := range tracks {
+		fmt.Fprintf(&b, "[%d:a:%d]", input, t)
+	}
+	fmt.Fprintf(&b, "amix=inputs=%d:duration=longest", len(tracks))
+	if weights != "" {
+		fmt.Fprintf(&b, ":weights='%s'", weights)
+	}
+	return b.String()
+}
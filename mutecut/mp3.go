@@ -0,0 +1,210 @@
+package mutecut
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// audioFormat is how -audio-format encodes extracted audio.
+type audioFormat struct {
+	Codec   string
+	Ext     string
+	Quality []string // Default quality options; lossless formats have none
+}
+
+var audioFormats = map[string]audioFormat{
+	"mp3":  {"libmp3lame", ".mp3", []string{"-q:a", "2"}}, // High quality variable bitrate
+	"aac":  {"aac", ".m4a", []string{"-b:a", "192k"}},
+	"flac": {"flac", ".flac", nil},
+	"wav":  {"pcm_s16le", ".wav", nil},
+	"opus": {"libopus", ".opus", []string{"-b:a", "128k"}},
+}
+
+func validateAudioFormat(name string) error {
+	if _, ok := audioFormats[name]; ok {
+		return nil
+	}
+	names := make([]string, 0, len(audioFormats))
+	for n := range audioFormats {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown -audio-format '%s' (one of: %s)", name, strings.Join(names, ", "))
+}
+
+// maxAudioBitrate caps -audio-quality bitrates; libmp3lame stops at 320k.
+var maxAudioBitrate = map[string]int{"mp3": 320, "aac": 512, "opus": 512}
+
+// audioQualityArgs turns -audio-quality into encoder options: a bare
+// number is a libmp3lame VBR level (0 best, 9 smallest), "128k" a
+// constant bitrate.
+func audioQualityArgs(quality, format string) ([]string, error) {
+	if kbps, ok := strings.CutSuffix(strings.ToLower(quality), "k"); ok {
+		limit, lossy := maxAudioBitrate[format]
+		if !lossy {
+			return nil, fmt.Errorf("-audio-format %s is lossless and takes no bitrate", format)
+		}
+		n, err := strconv.Atoi(kbps)
+		if err != nil || n < 8 || n > limit {
+			return nil, fmt.Errorf("-audio-quality bitrate for %s must be between 8k and %dk, got '%s'", format, limit, quality)
+		}
+		return []string{"-b:a", fmt.Sprintf("%dk", n)}, nil
+	}
+
+	level, err := strconv.Atoi(quality)
+	if err != nil {
+		return nil, fmt.Errorf("-audio-quality must be a VBR level (0-9) or a bitrate like 128k, got '%s'", quality)
+	}
+	if format != "mp3" {
+		return nil, fmt.Errorf("VBR levels only apply to mp3; use a bitrate like 128k for %s", format)
+	}
+	if level < 0 || level > 9 {
+		return nil, fmt.Errorf("-audio-quality VBR level must be between 0 and 9, got %d", level)
+	}
+	return []string{"-q:a", strconv.Itoa(level)}, nil
+}
+
+// audioCodecArgs returns the codec and quality options for the chosen
+// -audio-format, with -audio-quality (already validated) in place of the
+// format's default.
+func audioCodecArgs(cfg Config) []string {
+	format := audioFormats[cfg.AudioFormat]
+	quality := format.Quality
+	if cfg.AudioQuality != "" {
+		quality, _ = audioQualityArgs(cfg.AudioQuality, cfg.AudioFormat)
+	}
+	return append([]string{"-acodec", format.Codec}, quality...)
+}
+
+// audioOutputName returns the file extractAudio writes: the -o name with
+// the format's extension, or the input's name when -o is empty.
+func audioOutputName(cfg Config) string {
+	format := audioFormats[cfg.AudioFormat]
+
+	outputFile := cfg.OutputFile
+	if outputFile == "" {
+		ext := filepath.Ext(cfg.InputFile)
+		base := strings.TrimSuffix(cfg.InputFile, ext)
+		outputFile = base + format.Ext
+	} else {
+		// Ensure output ends with the format's extension
+		if !strings.HasSuffix(strings.ToLower(outputFile), format.Ext) {
+			outputFile += format.Ext
+		}
+	}
+	return outputFile
+}
+
+// validateAudioExtract rejects the audio options that take more than a
+// plain -af chain, which audio extraction doesn't build.
+func validateAudioExtract(cfg Config) error {
+	if !cfg.ExtractMP3 {
+		return nil
+	}
+	checks := []struct {
+		set  bool
+		name string
+	}{
+		{len(cfg.MixTracks) > 0, "-mix-audio"},
+		{cfg.DuckVoice != "", "-ducking-voice"},
+		{cfg.Beep, "-beep"},
+		{cfg.AudioDelay != 0, "-audio-delay/-auto-sync"},
+		{cfg.RemoveStart != "", "-remove-start/-remove-end"},
+		{cfg.TrimSilence, "-trim-silence"},
+		{cfg.Speed > 0 && cfg.Speed != 1, "-speed"},
+	}
+	var names []string
+	for _, c := range checks {
+		if c.set {
+			names = append(names, c.name)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("%s can't be applied to extracted audio; process the video and use -also-mp3 instead", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func extractAudio(cfg Config) error {
+	cfg.OutputFile = uniqueOutput(cfg, audioOutputName(cfg))
+
+	logf("Extracting %s audio to: %s\n", cfg.AudioFormat, cfg.OutputFile)
+
+	// ffmpeg -ss start -to end -i input.mp4 -vn -acodec libmp3lame -q:a 2 output.mp3
+	args := getInputArgs(cfg)
+	if cfg.AudioMap != "" {
+		args = append(args, "-map", cfg.AudioMap)
+	}
+	args = append(args, "-vn") // No video
+	args = append(args, audioCodecArgs(cfg)...)
+	// The same -af chain the video's audio gets
+	if filters, _ := audioFilters(cfg); len(filters) > 0 {
+		args = append(args, "-af", strings.Join(filters, ","))
+	}
+	if cfg.StripMetadata {
+		args = append(args, stripMetadataArgs...)
+	}
+	args = append(args,
+		"-y", // Overwrite
+		cfg.OutputFile,
+	)
+
+	return runFFmpeg(cfg, args)
+}
+
+// extractAudioByChapter writes one audio file per chapter, tagged with its track
+// number and the chapter title. Handy for full-album uploads.
+func extractAudioByChapter(cfg Config) ([]string, error) {
+	chapters, err := probeChapters(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("reading chapters: %w", err)
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("input has no chapters to split by")
+	}
+
+	ext := filepath.Ext(cfg.InputFile)
+	album := filepath.Base(strings.TrimSuffix(cfg.InputFile, ext))
+	dir := filepath.Dir(cfg.OutputFile)
+
+	logf("Splitting audio into %d chapters...\n", len(chapters))
+
+	var outputs []string
+	for i, ch := range chapters {
+		title := ch.Title
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		outputFile := uniqueOutput(cfg, filepath.Join(dir, fmt.Sprintf("%02d - %s%s", i+1, sanitizeFilename(title), audioFormats[cfg.AudioFormat].Ext)))
+		logf("[%d/%d] %s\n", i+1, len(chapters), outputFile)
+		cfg.Stage, cfg.Stages = i+1, len(chapters)
+		cfg.ExpectedDuration = ch.End - ch.Start
+
+		args := []string{
+			"-ss", fmt.Sprintf("%.3f", ch.Start),
+			"-to", fmt.Sprintf("%.3f", ch.End),
+		}
+		args = append(args, inputSourceArgs(cfg)...)
+		if cfg.AudioMap != "" {
+			args = append(args, "-map", cfg.AudioMap)
+		}
+		args = append(args, "-vn")
+		args = append(args, audioCodecArgs(cfg)...)
+		args = append(args,
+			"-map_chapters", "-1", // Each file is a single track, drop the chapter list
+			"-metadata", "title="+title,
+			"-metadata", "album="+album,
+			"-metadata", fmt.Sprintf("track=%d/%d", i+1, len(chapters)),
+			"-y",
+			outputFile,
+		)
+		if err := runFFmpeg(cfg, args); err != nil {
+			return outputs, err
+		}
+		outputs = append(outputs, outputFile)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,48 @@
+package mutecut
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAudioFiltersForExtraction(t *testing.T) {
+	cfg := Config{
+		ExtractMP3:     true,
+		StartTime:      "60",
+		MuteStart:      "70",
+		MuteEnd:        "75",
+		VolStart:       "80",
+		VolEnd:         "85",
+		VolLevel:       0.5,
+		Normalize:      true,
+		LoudnessTarget: -16,
+		EnhanceSpeech:  true,
+	}
+	filters, _ := audioFilters(cfg)
+	chain := strings.Join(filters, ",")
+	for _, want := range []string{"loudnorm", "between(t,10.000,15.000)", "between(t,20.000,25.000)"} {
+		if !strings.Contains(chain, want) {
+			t.Errorf("audio filters missing %q in: %s", want, chain)
+		}
+	}
+}
+
+func TestValidateAudioExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"plain", Config{ExtractMP3: true, Speed: 1}, false},
+		{"filters", Config{ExtractMP3: true, Normalize: true, MuteStart: "1", MuteEnd: "2"}, false},
+		{"mix", Config{ExtractMP3: true, MixTracks: []int{0, 1}}, true},
+		{"beep", Config{ExtractMP3: true, Beep: true}, true},
+		{"speed", Config{ExtractMP3: true, Speed: 2}, true},
+		{"video", Config{Beep: true, Speed: 2}, false},
+	}
+	for _, tt := range tests {
+		if err := validateAudioExtract(tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateAudioExtract error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
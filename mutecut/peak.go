@@ -0,0 +1,66 @@
+package mutecut
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+var maxVolumePattern = regexp.MustCompile(`max_volume:\s*(-?[0-9.]+|-inf) dB`)
+
+// measurePeakGain runs volumedetect over the selected audio and returns the
+// gain, in dB, that brings its loudest sample to ceiling dBFS. Unlike
+// loudness normalization this is a single linear gain: quick, and it never
+// clips, but it won't even out quiet and loud passages.
+func measurePeakGain(cfg Config, ceiling float64) (float64, error) {
+	args := append([]string{"-hide_banner", "-nostats"}, getInputArgs(cfg)...)
+	if cfg.AudioMap != "" {
+		args = append(args, "-map", cfg.AudioMap)
+	}
+	args = append(args, "-vn", "-af", "volumedetect", "-f", "null", "-")
+
+	cmd := exec.CommandContext(jobContext(cfg), cfg.FfmpegBin, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("peak analysis failed: %w", err)
+	}
+
+	m := maxVolumePattern.FindSubmatch(stderr.Bytes())
+	if m == nil {
+		return 0, fmt.Errorf("no audio found to measure")
+	}
+	if string(m[1]) == "-inf" {
+		return 0, fmt.Errorf("audio is silent, nothing to normalize")
+	}
+	peak, err := strconv.ParseFloat(string(m[1]), 64)
+	if err != nil {
+		return 0, err
+	}
+	return ceiling - peak, nil
+}
+
+// peakGainFilter applies the measured -peak-normalize gain.
+func peakGainFilter(gain float64) string {
+	return fmt.Sprintf("volume=%.2fdB", gain)
+}
+
+// defaultLoudnessTarget is the EBU R128 streaming target most platforms use.
+const defaultLoudnessTarget = -16.0
+
+// loudnormFilter normalizes loudness in a single pass. loudnorm works at
+// 192kHz internally, so the result is resampled back down.
+func loudnormFilter(target float64) string {
+	return fmt.Sprintf("loudnorm=I=%g:TP=-1.5:LRA=11,aresample=48000", target)
+}
+
+// validateLoudnessTarget checks -loudness-target against the range loudnorm
+// accepts.
+func validateLoudnessTarget(target float64) error {
+	if target < -70 || target > -5 {
+		return fmt.Errorf("-loudness-target must be between -70 and -5 LUFS, got %g", target)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package mutecut
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// runProbe runs ffprobe with args against the configured input and returns
+// its stdout. ffprobe is killed after cfg.ProbeTimeout (if set) so a broken
+// file or stalled stream can't hang the tool.
+func runProbe(cfg Config, args ...string) ([]byte, error) {
+	args = append(args, probeSourceArgs(cfg)...)
+
+	ctx := jobContext(cfg)
+	if cfg.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ProbeTimeout)
+		defer cancel()
+	}
+	out, err := exec.CommandContext(ctx, cfg.FfprobeBin, args...).Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("ffprobe timed out after %s on '%s' (raise -probe-timeout?)", cfg.ProbeTimeout, cfg.InputFile)
+	}
+	return out, err
+}
+
+// GetDuration asks ffprobe for the container duration of the input, in seconds.
+func GetDuration(cfg Config) (float64, error) {
+	out, err := runProbe(cfg,
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "csv=p=0",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	value := strings.TrimSpace(string(out))
+	if value == "" || value == "N/A" {
+		return 0, fmt.Errorf("ffprobe could not determine the duration of '%s'", cfg.InputFile)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+type probeStream struct {
+	Index     int               `json:"index"`
+	CodecType string            `json:"codec_type"`
+	CodecName string            `json:"codec_name"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	FrameRate string            `json:"r_frame_rate"`
+	BitRate   string            `json:"bit_rate"` // bits/s; not every container has it
+	Tags      map[string]string `json:"tags"`
+
+	ColorPrimaries string `json:"color_primaries"`
+	ColorTransfer  string `json:"color_transfer"`
+	ColorSpace     string `json:"color_space"`
+}
+
+// probeStreams lists every stream in the input along with its tags.
+func probeStreams(cfg Config) ([]probeStream, error) {
+	out, err := runProbe(cfg,
+		"-v", "error",
+		"-show_entries", "stream=index,codec_type,codec_name,width,height,r_frame_rate,bit_rate,color_primaries,color_transfer,color_space:stream_tags",
+		"-of", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result struct {
+		Streams []probeStream `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	return result.Streams, nil
+}
+
+// Chapter is a single chapter marker read from the input container.
+type Chapter struct {
+	Start float64
+	End   float64
+	Title string
+}
+
+// probeChapters returns the input's chapters in order (empty if it has none).
+func probeChapters(cfg Config) ([]Chapter, error) {
+	out, err := runProbe(cfg,
+		"-v", "error",
+		"-show_chapters",
+		"-of", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result struct {
+		Chapters []struct {
+			StartTime string            `json:"start_time"`
+			EndTime   string            `json:"end_time"`
+			Tags      map[string]string `json:"tags"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	chapters := make([]Chapter, 0, len(result.Chapters))
+	for _, c := range result.Chapters {
+		start, err := strconv.ParseFloat(c.StartTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad chapter start '%s': %w", c.StartTime, err)
+		}
+		end, err := strconv.ParseFloat(c.EndTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad chapter end '%s': %w", c.EndTime, err)
+		}
+		chapters = append(chapters, Chapter{Start: start, End: end, Title: c.Tags["title"]})
+	}
+	return chapters, nil
+}
+
+// probeKeyframes returns the timestamps, in seconds, of every keyframe in
+// the first video stream. ffprobe still has to walk the whole file, but
+// skipping non-key frames keeps it quick.
+func probeKeyframes(cfg Config) ([]float64, error) {
+	out, err := runProbe(cfg,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-skip_frame", "nokey",
+		"-show_entries", "frame=best_effort_timestamp_time",
+		"-of", "csv=p=0",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var times []float64
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ","))
+		if line == "" || line == "N/A" {
+			continue
+		}
+		t, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected ffprobe output '%s'", line)
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
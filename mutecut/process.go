@@ -0,0 +1,277 @@
+package mutecut
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Result describes the files a job wrote.
+type Result struct {
+	Output       string
+	ExtraOutputs []string // Further pieces, chapters or -also-* outputs
+	Elapsed      time.Duration
+	Hashes       map[string]string // Output path -> SHA-256, with cfg.Hash
+}
+
+// Process runs the job described by cfg: a cut/mute encode, or whichever
+// mode (audio extraction, splitting, joining, GIF...) cfg selects. It
+// expects a Config that has passed Validate, as the CLI's always has;
+// embedders should fill in at least InputFile and OutputFile. Options left
+// at zero get the CLI's defaults (see DefaultConfig), and FfmpegBin/
+// FfprobeBin are looked up like the CLI does when empty.
+func Process(cfg Config) (Result, error) {
+	cfg, err := withDefaults(cfg)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if cfg.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(jobContext(cfg), cfg.Timeout)
+		defer cancel()
+		cfg.Ctx = ctx
+	}
+
+	switch {
+	case cfg.SplitSilence || cfg.SplitByChapter && cfg.ExtractMP3:
+		// Each piece gets its own name
+	case cfg.ExtractMP3:
+		cfg.OutputFile = uniqueOutput(cfg, audioOutputName(cfg))
+	default:
+		cfg.OutputFile = uniqueOutput(cfg, cfg.OutputFile)
+	}
+
+	start := time.Now()
+	cfg.ExpectedDuration = expectedOutputDuration(cfg)
+	cfg.JobStart = start
+	cfg.Stage, cfg.Stages = 1, countStages(cfg)
+
+	logln("Mode: Processing (Cut/Mute)...")
+	var extraOutputs []string
+	if len(cfg.ConcatFiles) > 0 {
+		err = concatFiles(cfg)
+	} else if cfg.SplitSilence || (cfg.ExtractMP3 && cfg.SplitByChapter) {
+		var outputs []string
+		if cfg.SplitSilence {
+			outputs, err = splitOnSilence(cfg)
+		} else {
+			outputs, err = extractAudioByChapter(cfg)
+		}
+		if err == nil {
+			cfg.OutputFile, extraOutputs = outputs[0], outputs[1:]
+		}
+	} else if cfg.ExtractMP3 {
+		err = extractAudio(cfg)
+	} else if cfg.Repair {
+		err = repairFile(cfg)
+	} else if cfg.ExportWebP {
+		err = exportWebP(cfg)
+	} else if cfg.ExportGIF {
+		err = exportGIF(cfg)
+	} else if err = simpleCut(cfg); err == nil {
+		extraOutputs, err = renderExtraOutputs(cfg)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Output: cfg.OutputFile, ExtraOutputs: extraOutputs, Elapsed: time.Since(start)}
+	if cfg.Hash && !cfg.DryRun {
+		result.Hashes, err = hashOutputs(append([]string{result.Output}, extraOutputs...), cfg.HashSidecar)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Validate runs the checks the command line makes on its options against
+// cfg, returning the first problem found. Some of them probe the input, so
+// InputFile must exist.
+func Validate(cfg Config) error {
+	cfg, err := withDefaults(cfg)
+	if err != nil {
+		return err
+	}
+	parse := ParseTimeToSeconds
+	if cfg.StrictTime {
+		parse = parseTimeStrict
+	}
+	checks := []func() error{
+		func() error { return validateTimeFlags(cfg, parse) },
+		func() error { return validateAudioFormat(cfg.AudioFormat) },
+		func() error { return validateChapterSplit(cfg) },
+		func() error { return validateAudioExtract(cfg) },
+		func() error {
+			if cfg.AudioQuality == "" {
+				return nil
+			}
+			_, err := audioQualityArgs(cfg.AudioQuality, cfg.AudioFormat)
+			return err
+		},
+		func() error {
+			if !cfg.ExportWebP {
+				return nil
+			}
+			return validateWebP(cfg)
+		},
+		func() error {
+			if !cfg.ExportGIF && !cfg.AlsoGIF {
+				return nil
+			}
+			return validateGIF(cfg)
+		},
+		func() error { return validateSquare(cfg) },
+		func() error { return validateReplace(cfg) },
+		func() error { return validateAutoMute(cfg) },
+		func() error { return validateWatermark(cfg) },
+		func() error { return validateSubs(cfg) },
+		func() error { return validateBeep(cfg) },
+		func() error { return validateSlate(cfg) },
+		func() error { return validateSpeed(cfg.Speed) },
+		func() error { return validateCrop(cfg) },
+		func() error { return validateResolution(cfg) },
+		func() error { return validateRemove(cfg) },
+		func() error { return validatePreset(cfg.Preset) },
+		func() error { return validateCRF(cfg.VideoCodec, cfg.CRF) },
+		func() error { return validateBitDepth(cfg) },
+		func() error { return validateCodecs(cfg) },
+		func() error { return validateFade(cfg) },
+		func() error { return checkMaxLen(cfg) },
+		func() error {
+			if cfg.ImportChapters == "" {
+				return nil
+			}
+			return validateChapterFile(cfg.ImportChapters)
+		},
+		func() error {
+			if !cfg.Normalize {
+				return nil
+			}
+			return validateLoudnessTarget(cfg.LoudnessTarget)
+		},
+		func() error {
+			if !cfg.StreamCopy {
+				return nil
+			}
+			return validateStreamCopy(cfg)
+		},
+	}
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withDefaults fills in what a Config built outside the CLI may leave
+// empty with the CLI's defaults: the ffmpeg/ffprobe paths, speed, preset and
+// audio format.
+func withDefaults(cfg Config) (Config, error) {
+	if cfg.FfmpegBin == "" {
+		cfg.FfmpegBin = resolveBinary("ffmpeg")
+	}
+	if cfg.FfprobeBin == "" {
+		cfg.FfprobeBin = resolveBinary("ffprobe")
+	}
+	if cfg.FfmpegBin == "" || cfg.FfprobeBin == "" {
+		return cfg, fmt.Errorf("ffmpeg or ffprobe not found in 'bin' folder or system PATH")
+	}
+	return applyDefaults(cfg), nil
+}
+
+// DefaultConfig returns the options the CLI runs with when no flags are
+// given. The flags take their defaults from it. Start from it rather than
+// a bare Config to ask for a zero that differs from the default, such as
+// CRF 0 (lossless) or VolLevel 0.
+func DefaultConfig() Config {
+	return Config{
+		CRF:              23,
+		Preset:           "medium",
+		BitDepth:         8,
+		Speed:            1,
+		VolLevel:         0.3,
+		BeepFreq:         defaultBeepFreq,
+		SubsMode:         "soft",
+		WatermarkPos:     "br",
+		WatermarkMargin:  10,
+		WatermarkOpacity: 1,
+		SlateDuration:    3,
+		SlateColor:       "black",
+		SlateTextColor:   "white",
+		SquareMode:       "crop",
+		SquareColor:      "black",
+		LoudnessTarget:   defaultLoudnessTarget,
+		AudioFormat:      "mp3",
+		StatsInterval:    time.Second,
+		ProbeTimeout:     30 * time.Second,
+		SilenceMin:       2,
+		SilenceNoise:     -40,
+		WebPFPS:          15,
+		WebPWidth:        480,
+		WebPQuality:      75,
+		GifFPS:           defaultGifFPS,
+		GifWidth:         defaultGifWidth,
+		TestDuration:     10,
+		ServePort:        8080,
+
+		defaulted: true,
+	}
+}
+
+// applyDefaults fills the zero options of a Config that wasn't built from
+// DefaultConfig (or by the CLI) with the defaults.
+func applyDefaults(cfg Config) Config {
+	if cfg.defaulted {
+		return cfg
+	}
+	d := DefaultConfig()
+	cfg.CRF = cmp.Or(cfg.CRF, d.CRF)
+	cfg.Preset = cmp.Or(cfg.Preset, d.Preset)
+	cfg.BitDepth = cmp.Or(cfg.BitDepth, d.BitDepth)
+	cfg.Speed = cmp.Or(cfg.Speed, d.Speed)
+	cfg.VolLevel = cmp.Or(cfg.VolLevel, d.VolLevel)
+	cfg.BeepFreq = cmp.Or(cfg.BeepFreq, d.BeepFreq)
+	cfg.SubsMode = cmp.Or(cfg.SubsMode, d.SubsMode)
+	cfg.WatermarkPos = cmp.Or(cfg.WatermarkPos, d.WatermarkPos)
+	cfg.WatermarkMargin = cmp.Or(cfg.WatermarkMargin, d.WatermarkMargin)
+	cfg.WatermarkOpacity = cmp.Or(cfg.WatermarkOpacity, d.WatermarkOpacity)
+	cfg.SlateDuration = cmp.Or(cfg.SlateDuration, d.SlateDuration)
+	cfg.SlateColor = cmp.Or(cfg.SlateColor, d.SlateColor)
+	cfg.SlateTextColor = cmp.Or(cfg.SlateTextColor, d.SlateTextColor)
+	cfg.SquareMode = cmp.Or(cfg.SquareMode, d.SquareMode)
+	cfg.SquareColor = cmp.Or(cfg.SquareColor, d.SquareColor)
+	cfg.LoudnessTarget = cmp.Or(cfg.LoudnessTarget, d.LoudnessTarget)
+	cfg.AudioFormat = cmp.Or(cfg.AudioFormat, d.AudioFormat)
+	cfg.StatsInterval = cmp.Or(cfg.StatsInterval, d.StatsInterval)
+	cfg.ProbeTimeout = cmp.Or(cfg.ProbeTimeout, d.ProbeTimeout)
+	cfg.SilenceMin = cmp.Or(cfg.SilenceMin, d.SilenceMin)
+	cfg.SilenceNoise = cmp.Or(cfg.SilenceNoise, d.SilenceNoise)
+	cfg.WebPFPS = cmp.Or(cfg.WebPFPS, d.WebPFPS)
+	cfg.WebPWidth = cmp.Or(cfg.WebPWidth, d.WebPWidth)
+	cfg.WebPQuality = cmp.Or(cfg.WebPQuality, d.WebPQuality)
+	cfg.GifFPS = cmp.Or(cfg.GifFPS, d.GifFPS)
+	cfg.GifWidth = cmp.Or(cfg.GifWidth, d.GifWidth)
+	cfg.TestDuration = cmp.Or(cfg.TestDuration, d.TestDuration)
+	cfg.ServePort = cmp.Or(cfg.ServePort, d.ServePort)
+	cfg.defaulted = true
+	return cfg
+}
+
+// jobContext returns cfg.Ctx, or a context that is never cancelled.
+func jobContext(cfg Config) context.Context {
+	if cfg.Ctx != nil {
+		return cfg.Ctx
+	}
+	return context.Background()
+}
+
+// cancelError explains why ffmpeg was stopped once ctx is done.
+func cancelError(cfg Config, ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("job timed out after %s (-timeout); partial output removed", cfg.Timeout)
+	}
+	return fmt.Errorf("interrupted; partial output removed")
+}
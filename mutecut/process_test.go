@@ -0,0 +1,63 @@
+package mutecut
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateRejectsBadOptions(t *testing.T) {
+	base := Config{
+		InputFile:  "in.mp4",
+		OutputFile: "out.mp4",
+		FfmpegBin:  "ffmpeg",
+		FfprobeBin: "ffprobe",
+		CRF:        23,
+	}
+	tests := []struct {
+		name string
+		edit func(*Config)
+	}{
+		{"bad time", func(c *Config) { c.StartTime = "00:ab:30" }},
+		{"crf out of range", func(c *Config) { c.CRF = 99 }},
+		{"unknown preset", func(c *Config) { c.Preset = "warp" }},
+		{"partial replace", func(c *Config) { c.ReplaceStart = "10" }},
+		{"chapter split without -mp3", func(c *Config) { c.SplitByChapter = true }},
+	}
+	for _, tt := range tests {
+		cfg := base
+		tt.edit(&cfg)
+		if err := Validate(cfg); err == nil {
+			t.Errorf("%s: Validate accepted %+v", tt.name, cfg)
+		}
+	}
+}
+
+func TestMinimalConfigGetsCLIDefaults(t *testing.T) {
+	minimal := Config{InputFile: "in.mp4", OutputFile: "out.mp4", FfmpegBin: "ffmpeg", FfprobeBin: "ffprobe"}
+	got, err := withDefaults(minimal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What the CLI builds when given only -i and -o
+	cli := DefaultConfig()
+	cli.InputFile, cli.OutputFile = minimal.InputFile, minimal.OutputFile
+	cli.FfmpegBin, cli.FfprobeBin = minimal.FfmpegBin, minimal.FfprobeBin
+
+	if gotArgs, wantArgs := simpleCutArgs(got), simpleCutArgs(cli); !slices.Equal(gotArgs, wantArgs) {
+		t.Errorf("minimal Config args:\n%q\nCLI args:\n%q", gotArgs, wantArgs)
+	}
+	if args := strings.Join(simpleCutArgs(got), " "); !strings.Contains(args, "-crf 23") {
+		t.Errorf("minimal Config doesn't encode at the default CRF: %s", args)
+	}
+}
+
+func TestDefaultConfigKeepsZeros(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CRF, cfg.VolLevel = 0, 0
+	got := applyDefaults(cfg)
+	if got.CRF != 0 || got.VolLevel != 0 {
+		t.Errorf("applyDefaults replaced zeros asked for on a DefaultConfig: CRF %d, VolLevel %g", got.CRF, got.VolLevel)
+	}
+}
@@ -0,0 +1,232 @@
+package mutecut
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// progressArgs make ffmpeg report machine-readable progress on stdout and
+// keep its own console output down to errors, so they don't fight over the
+// terminal.
+var progressArgs = []string{"-progress", "pipe:1", "-nostats", "-hide_banner", "-loglevel", "error"}
+
+// runWithProgress runs cmd (which must have been started with progressArgs)
+// and draws a progress bar from its out_time reports. When the expected
+// duration is unknown (e.g. live capture) it shows a spinner with the time
+// and size written so far instead.
+//
+// A job can take several ffmpeg runs (two-pass GIFs, extra outputs...). Each
+// run is one stage of cfg.Stages, so the bar and ETA cover the whole job
+// rather than jumping back to 0% at the start of every pass.
+func runWithProgress(cmd *exec.Cmd, cfg Config) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	stage, stages := cfg.Stage, cfg.Stages
+	if stages < 1 {
+		stage, stages = 1, 1
+	}
+	jobStart := cfg.JobStart
+	if jobStart.IsZero() {
+		jobStart = time.Now()
+	}
+
+	// Redraw at most once per interval; the final 100% always gets through.
+	tty := isTerminal(os.Stdout)
+	var lastDraw time.Time
+	spin := 0
+	readProgress(stdout, func(p progressReport) {
+		if cfg.ExpectedDuration <= 0 {
+			if !p.Done && time.Since(lastDraw) < cfg.StatsInterval {
+				return
+			}
+			lastDraw = time.Now()
+			drawSpinner(spin, p, tty)
+			spin++
+			return
+		}
+
+		frac := p.OutTime / cfg.ExpectedDuration
+		if frac > 1 || p.Done {
+			frac = 1
+		}
+		if frac < 1 && time.Since(lastDraw) < cfg.StatsInterval {
+			return
+		}
+		lastDraw = time.Now()
+		overall := (float64(stage-1) + frac) / float64(stages)
+		drawProgress(stage, stages, overall, p.TotalSize, jobStart, tty)
+	})
+
+	err = cmd.Wait()
+	if tty {
+		progressf("\n")
+	}
+	return err
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a
+// pipe or file (e.g. output captured into a CI log).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressReport is one block of ffmpeg's -progress output.
+type progressReport struct {
+	OutTime   float64 // Seconds of output written
+	TotalSize int64   // Bytes written so far
+	Done      bool
+}
+
+// readProgress calls update at the end of each -progress block (marked by
+// a "progress=" line) with the latest out_time_us (or older out_time_ms,
+// also in microseconds) and total_size values.
+func readProgress(r io.Reader, update func(p progressReport)) {
+	var p progressReport
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "out_time_us", "out_time_ms":
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				p.OutTime = float64(us) / 1e6 // "N/A" before the first frame is skipped
+			}
+		case "total_size":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				p.TotalSize = n
+			}
+		case "progress":
+			p.Done = value == "end"
+			update(p)
+		}
+	}
+}
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// drawSpinner shows activity when there's no total to measure against.
+func drawSpinner(frame int, p progressReport, tty bool) {
+	status := fmt.Sprintf("%s written, %s", formatTimestamp(p.OutTime), formatSize(p.TotalSize))
+	if !tty {
+		progressf("progress %s\n", status)
+		return
+	}
+	progressf("\r[%s] %s   ", spinnerFrames[frame%len(spinnerFrames)], status)
+}
+
+func formatSize(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+}
+
+// drawProgress redraws the bar in place on a terminal; elsewhere it prints
+// one plain line per update so logs stay readable.
+func drawProgress(stage, stages int, overall float64, size int64, jobStart time.Time, tty bool) {
+	filled := int(overall * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+
+	eta := "--"
+	if elapsed := time.Since(jobStart); overall > 0.01 {
+		remaining := time.Duration(float64(elapsed) / overall * (1 - overall))
+		eta = remaining.Round(time.Second).String()
+	}
+
+	prefix := ""
+	if stages > 1 {
+		prefix = fmt.Sprintf("Pass %d/%d ", stage, stages)
+	}
+	if !tty {
+		progressf("%sprogress %5.1f%%  %s  ETA %s\n", prefix, overall*100, formatSize(size), eta)
+		return
+	}
+	progressf("\r%s[%s] %5.1f%%  %9s  ETA %-8s", prefix, bar, overall*100, formatSize(size), eta)
+}
+
+// Download progress redraws this often on a terminal, and logs a line this
+// often otherwise.
+const (
+	downloadDrawInterval = 250 * time.Millisecond
+	downloadLineInterval = 5 * time.Second
+)
+
+// downloadProgress passes a download through, showing the percentage and
+// speed when the total size is known and the bytes so far when it isn't.
+type downloadProgress struct {
+	r        io.Reader
+	done     int64 // Bytes so far, counting any resumed part
+	resumed  int64 // Bytes already on disk, left out of the speed
+	total    int64 // 0 if unknown
+	start    time.Time
+	lastDraw time.Time
+	inPlace  bool // Redraw one line rather than logging a line per update
+}
+
+func newDownloadProgress(r io.Reader, offset, total int64, verbose bool) *downloadProgress {
+	return &downloadProgress{
+		r:       r,
+		done:    offset,
+		resumed: offset,
+		total:   total,
+		start:   time.Now(),
+		inPlace: !verbose && isTerminal(os.Stdout),
+	}
+}
+
+func (d *downloadProgress) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.done += int64(n)
+	interval := downloadLineInterval
+	if d.inPlace {
+		interval = downloadDrawInterval
+	}
+	if time.Since(d.lastDraw) >= interval {
+		d.lastDraw = time.Now()
+		d.draw()
+	}
+	return n, err
+}
+
+func (d *downloadProgress) draw() {
+	status := formatSize(d.done) + " downloaded"
+	if d.total > 0 {
+		frac := min(float64(d.done)/float64(d.total), 1)
+		status = fmt.Sprintf("%5.1f%%  %s / %s", frac*100, formatSize(d.done), formatSize(d.total))
+		if d.inPlace {
+			filled := int(frac * progressBarWidth)
+			status = "[" + strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled) + "] " + status
+		}
+	}
+	if elapsed := time.Since(d.start).Seconds(); elapsed > 0 {
+		status += fmt.Sprintf("  %s/s", formatSize(int64(float64(d.done-d.resumed)/elapsed)))
+	}
+
+	if d.inPlace {
+		progressf("\r%s   ", status)
+		return
+	}
+	progressf("download %s\n", status)
+}
+
+// finish draws the final state and ends the in-place line.
+func (d *downloadProgress) finish() {
+	d.draw()
+	if d.inPlace {
+		progressf("\n")
+	}
+}
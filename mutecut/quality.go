@@ -0,0 +1,49 @@
+package mutecut
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// qualityProfile is a coherent encoder setup picked by -quality.
+type qualityProfile struct {
+	Codec  string
+	Preset string
+	CRF    int
+}
+
+var qualityProfiles = map[string]qualityProfile{
+	"fast":     {"libx264", "veryfast", 23},
+	"balanced": {"libx264", "medium", 23},
+	"small":    {"libx265", "medium", 28},
+	"archive":  {"libx264", "slow", 18},
+}
+
+// applyQuality fills in codec, preset and CRF from the -quality profile.
+// Flags given explicitly (on the command line or via MUTECUT_*) win.
+func applyQuality(cfg *Config, name string, explicit map[string]bool) error {
+	profile, ok := qualityProfiles[name]
+	if !ok {
+		names := make([]string, 0, len(qualityProfiles))
+		for n := range qualityProfiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown -quality '%s' (one of: %s)", name, strings.Join(names, ", "))
+	}
+
+	cfg.VideoCodec = profile.Codec
+	if !explicit["preset"] {
+		cfg.Preset = profile.Preset
+	}
+	if !explicit["crf"] {
+		cfg.CRF = profile.CRF
+	}
+	if cfg.VideoCodec != "libx264" && !hasEncoder(*cfg, cfg.VideoCodec) {
+		return fmt.Errorf("-quality %s needs an ffmpeg build with %s", name, cfg.VideoCodec)
+	}
+
+	logf("Quality '%s': %s, preset %s, CRF %d, yuv420p\n", name, cfg.VideoCodec, cfg.Preset, cfg.CRF)
+	return nil
+}
@@ -0,0 +1,42 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// repairFile remuxes the input into a fresh container. Regenerated
+// timestamps and a rewritten, front-loaded index fix most files that were
+// cut off mid-download or never properly closed. With -repair-reencode a
+// failed remux is retried as a full re-encode.
+func repairFile(cfg Config) error {
+	logf("Repairing into: %s\n", cfg.OutputFile)
+
+	args := []string{"-fflags", "+genpts+discardcorrupt", "-err_detect", "ignore_err"}
+	args = append(args, inputSourceArgs(cfg)...)
+	args = append(args,
+		"-map", "0:v?", "-map", "0:a?",
+		"-c", "copy",
+		"-movflags", "+faststart",
+		"-y", cfg.OutputFile,
+	)
+	err := runFFmpeg(cfg, args)
+	if err == nil {
+		return nil
+	}
+	if !cfg.RepairReencode {
+		return fmt.Errorf("remux failed; retry with -repair-reencode to re-encode instead: %w", err)
+	}
+
+	logf("Remux failed (%v), re-encoding instead...\n", err)
+	args = []string{"-fflags", "+genpts+discardcorrupt", "-err_detect", "ignore_err"}
+	args = append(args, inputSourceArgs(cfg)...)
+	args = append(args,
+		"-map", "0:v?", "-map", "0:a?",
+		"-c:v", "libx264", "-preset", cfg.Preset, "-crf", strconv.Itoa(cfg.CRF),
+		"-c:a", "aac",
+		"-movflags", "+faststart",
+		"-y", cfg.OutputFile,
+	)
+	return runFFmpeg(cfg, args)
+}
@@ -0,0 +1,53 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".bmp": true, ".webp": true,
+}
+
+// replaceInputArgs returns the extra ffmpeg input for -replace-with: a
+// looped still image, a video clip, or (if it isn't a file) a solid colour.
+func replaceInputArgs(with string) []string {
+	if _, err := os.Stat(with); err == nil {
+		if imageExtensions[strings.ToLower(filepath.Ext(with))] {
+			return []string{"-loop", "1", "-i", with}
+		}
+		return []string{"-i", with}
+	}
+	return []string{"-f", "lavfi", "-i", "color=c=" + with}
+}
+
+// replaceFilterGraph overlays input #idx on top of the main video between
+// start and end. The replacement is scaled to the main video's size and its
+// timestamps are shifted so a clip's first frame lands at shift: the start of
+// the range, or earlier when -start cut into it.
+// The result is left unlabelled so callers can chain more filters onto it.
+func replaceFilterGraph(idx int, shift, start, end float64) string {
+	return fmt.Sprintf(
+		"[%d:v]setpts=PTS-STARTPTS%+.3f/TB[rep];"+
+			"[rep][0:v]scale2ref[rep_scaled][base];"+
+			"[base][rep_scaled]overlay=eof_action=pass:enable='between(t,%.3f,%.3f)'",
+		idx, shift, start, end)
+}
+
+func validateReplace(cfg Config) error {
+	set := 0
+	for _, v := range []string{cfg.ReplaceStart, cfg.ReplaceEnd, cfg.ReplaceWith} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 0 && set != 3 {
+		return fmt.Errorf("-replace-start, -replace-end and -replace-with must be used together")
+	}
+	if set == 3 && toSeconds(cfg.ReplaceEnd) <= toSeconds(cfg.ReplaceStart) {
+		return fmt.Errorf("-replace-end must be after -replace-start")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package mutecut
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolutionPresets are the -resolution names and the box (width x height)
+// the output is fitted inside, keeping its aspect ratio.
+var resolutionPresets = map[string][2]int{
+	"480p":          {854, 480},
+	"720p":          {1280, 720},
+	"1080p":         {1920, 1080},
+	"1440p":         {2560, 1440},
+	"4k":            {3840, 2160},
+	"vertical-720":  {720, 1280},
+	"vertical-1080": {1080, 1920},
+}
+
+// resolutionFilter scales into the preset's box without distorting the
+// picture; both sides are rounded to even numbers as x264/x265 require.
+func resolutionFilter(name string) string {
+	box := resolutionPresets[name]
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease:force_divisible_by=2", box[0], box[1])
+}
+
+func validateResolution(cfg Config) error {
+	if cfg.Resolution == "" {
+		return nil
+	}
+	box, ok := resolutionPresets[cfg.Resolution]
+	if !ok {
+		names := make([]string, 0, len(resolutionPresets))
+		for name := range resolutionPresets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown -resolution '%s' (one of: %s)", cfg.Resolution, strings.Join(names, ", "))
+	}
+	if cfg.SquareSize > 0 {
+		return fmt.Errorf("-resolution and -square cannot be combined")
+	}
+	if cfg.AllowUpscale {
+		return nil
+	}
+
+	width, height, _, err := probeVideoGeometry(cfg)
+	if err != nil {
+		return err
+	}
+	if r, err := parseCrop(cfg.Crop); cfg.Crop != "" && err == nil {
+		width, height = r.W, r.H // The crop is what gets scaled
+	}
+	if width < box[0] && height < box[1] {
+		return fmt.Errorf("-resolution %s would upscale the %dx%d input; add -allow-upscale to do it anyway",
+			cfg.Resolution, width, height)
+	}
+	return nil
+}
+
+// scaleFilter turns -scale into a scale filter. It takes an exact size
+// ("1280x720"), one side with the other following the aspect ratio
+// ("1280x", "x720" or "720p"), or a factor ("0.5"). Derived sides use -2 so
+// they come out even.
+func scaleFilter(spec string) (string, error) {
+	if lines, ok := strings.CutSuffix(strings.ToLower(spec), "p"); ok {
+		h, err := scaleSide(lines)
+		if err != nil || h == 0 {
+			return "", fmt.Errorf("invalid -scale '%s'", spec)
+		}
+		return fmt.Sprintf("scale=-2:%d", h), nil
+	}
+
+	if w, h, ok := strings.Cut(spec, "x"); ok {
+		width, err := scaleSide(w)
+		if err != nil {
+			return "", fmt.Errorf("invalid -scale width in '%s': %v", spec, err)
+		}
+		height, err := scaleSide(h)
+		if err != nil {
+			return "", fmt.Errorf("invalid -scale height in '%s': %v", spec, err)
+		}
+		if width == 0 && height == 0 {
+			return "", fmt.Errorf("-scale '%s' needs at least one side", spec)
+		}
+		return fmt.Sprintf("scale=%d:%d", orAuto(width), orAuto(height)), nil
+	}
+
+	factor, err := strconv.ParseFloat(spec, 64)
+	if err != nil || factor <= 0 {
+		return "", fmt.Errorf("invalid -scale '%s' (use WxH, 720p or a factor like 0.5)", spec)
+	}
+	return fmt.Sprintf("scale=trunc(iw*%g/2)*2:trunc(ih*%g/2)*2", factor, factor), nil
+}
+
+// scaleSide parses one -scale dimension; empty means "follow the aspect
+// ratio" and comes back as 0.
+func scaleSide(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a positive number", s)
+	}
+	if n%2 != 0 {
+		return 0, fmt.Errorf("%d is odd; H.264 needs even sizes", n)
+	}
+	return n, nil
+}
+
+func orAuto(side int) int {
+	if side == 0 {
+		return -2
+	}
+	return side
+}
@@ -0,0 +1,55 @@
+package mutecut
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// serveOutput exposes the finished output over HTTP until ctx is cancelled
+// (Ctrl+C). ServeFile takes care of the Content-Type and Range requests, so
+// browsers can seek through the video while it streams.
+func serveOutput(ctx context.Context, path string, port int) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot serve output: %w", err)
+	}
+
+	name := "/" + filepath.Base(path)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && r.URL.Path != name {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, path)
+	})
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	fmt.Printf("Serving output at http://localhost:%d%s (Ctrl+C to stop)\n", port, name)
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		logln("\nShutting down server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,150 @@
+package mutecut
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// setupArchive is a zip holding one or more of the ffmpeg binaries. Its
+// SHA-256 is published beside it as <URL>.sha256.
+type setupArchive struct {
+	URL      string
+	Binaries []string
+}
+
+// rbURL is a static build from ffmpeg.martin-riedl.de, one zip per binary.
+func rbURL(goos, arch, binary string) string {
+	return fmt.Sprintf("https://ffmpeg.martin-riedl.de/redirect/latest/%s/%s/release/%s.zip", goos, arch, binary)
+}
+
+// ffmpegBuilds lists where -setup gets ffmpeg and ffprobe for each
+// GOOS/GOARCH. The Windows build is the same one setup_ffmpeg.ps1 uses.
+var ffmpegBuilds = map[string][]setupArchive{
+	"windows/amd64": {{URL: "https://www.gyan.dev/ffmpeg/builds/ffmpeg-release-essentials.zip", Binaries: []string{"ffmpeg.exe", "ffprobe.exe"}}},
+	"linux/amd64":   {{URL: rbURL("linux", "amd64", "ffmpeg"), Binaries: []string{"ffmpeg"}}, {URL: rbURL("linux", "amd64", "ffprobe"), Binaries: []string{"ffprobe"}}},
+	"linux/arm64":   {{URL: rbURL("linux", "arm64", "ffmpeg"), Binaries: []string{"ffmpeg"}}, {URL: rbURL("linux", "arm64", "ffprobe"), Binaries: []string{"ffprobe"}}},
+	"darwin/amd64":  {{URL: rbURL("macos", "amd64", "ffmpeg"), Binaries: []string{"ffmpeg"}}, {URL: rbURL("macos", "amd64", "ffprobe"), Binaries: []string{"ffprobe"}}},
+	"darwin/arm64":  {{URL: rbURL("macos", "arm64", "ffmpeg"), Binaries: []string{"ffmpeg"}}, {URL: rbURL("macos", "arm64", "ffprobe"), Binaries: []string{"ffprobe"}}},
+}
+
+// setupFFmpeg downloads ffmpeg and ffprobe for this OS/arch into dir (the
+// "bin" folder resolveBinary looks in), checking each archive's checksum.
+func setupFFmpeg(dir string) error {
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	archives, ok := ffmpegBuilds[platform]
+	if !ok {
+		return fmt.Errorf("no ffmpeg download known for %s; install ffmpeg with your package manager instead", platform)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, a := range archives {
+		if err := installArchive(a, dir); err != nil {
+			return err
+		}
+	}
+	logf("Done! ffmpeg and ffprobe installed to %s\n", dir)
+	return nil
+}
+
+// installArchive downloads a, verifies it and extracts its binaries to dir.
+func installArchive(a setupArchive, dir string) error {
+	want, err := fetchChecksum(a.URL + ".sha256")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "download-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	logf("Downloading %s...\n", a.URL)
+	resp, err := http.Get(a.URL)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download of %s failed: %s", a.URL, resp.Status)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s (got %s, expected %s)", a.URL, got, want)
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", a.URL, err)
+	}
+	for _, name := range a.Binaries {
+		if err := extractZipBinary(zr, name, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchChecksum returns the hex SHA-256 from a "<hash>  <file>" style file.
+func fetchChecksum(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch checksum: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot fetch checksum %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch checksum: %w", err)
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("unexpected checksum file at %s", url)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// extractZipBinary writes the entry named name (at any depth) to dest,
+// marking it executable.
+func extractZipBinary(zr *zip.Reader, name, dest string) error {
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != name {
+			continue
+		}
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, src); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+		return os.Chmod(dest, 0o755) // In case dest already existed without +x
+	}
+	return fmt.Errorf("%s not found in the downloaded archive", name)
+}
@@ -0,0 +1,66 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// drawtextEscaper escapes a title for use inside a quoted drawtext value.
+var drawtextEscaper = strings.NewReplacer(`\`, `\\`, "'", `'\''`, ":", `\:`, "%", `\%`)
+
+// slateInputArgs generates the slate's picture (a solid colour, or a still
+// image held for the duration) and a matching stretch of silence.
+func slateInputArgs(cfg Config, fps float64) []string {
+	duration := fmt.Sprintf("%.3f", cfg.SlateDuration)
+	var args []string
+	if cfg.SlateImage != "" {
+		args = []string{"-loop", "1", "-framerate", fmt.Sprintf("%.3f", fps), "-t", duration, "-i", cfg.SlateImage}
+	} else {
+		args = []string{"-f", "lavfi", "-t", duration, "-i", fmt.Sprintf("color=c=%s:r=%.3f", cfg.SlateColor, fps)}
+	}
+	return append(args, "-f", "lavfi", "-t", duration, "-i", "anullsrc=r=48000:cl=stereo")
+}
+
+// slateGraph prepends the slate (inputs slateInput and slateInput+1) to the
+// main video and audio. The slate is scaled to the main picture's size so
+// the two can be concatenated. pre/apre are optional filter chains for the
+// main streams; audio may be empty when the input has none.
+func slateGraph(cfg Config, slateInput int, video, pre, audio, apre string) string {
+	var parts []string
+	head := video
+	if pre != "" {
+		head += pre + ","
+	}
+	parts = append(parts, head+"null[slmain]")
+
+	slate := fmt.Sprintf("[%d:v][slmain]scale2ref[slbg][slref];[slbg]setsar=1,format=yuv420p", slateInput)
+	if cfg.IntroSlate != "" {
+		slate += fmt.Sprintf(",drawtext=text='%s':fontcolor=%s:fontsize=h/12:x=(w-text_w)/2:y=(h-text_h)/2",
+			drawtextEscaper.Replace(cfg.IntroSlate), cfg.SlateTextColor)
+	}
+	parts = append(parts, slate+"[slv]")
+
+	if audio == "" {
+		parts = append(parts, "[slv][slref]concat=n=2:v=1:a=0[sv]")
+		return strings.Join(parts, ";")
+	}
+	ahead := audio
+	if apre != "" {
+		ahead += apre + ","
+	}
+	parts = append(parts,
+		ahead+"anull[slamain]",
+		fmt.Sprintf("[slv][%d:a][slref][slamain]concat=n=2:v=1:a=1[sv][sa]", slateInput+1),
+	)
+	return strings.Join(parts, ";")
+}
+
+func validateSlate(cfg Config) error {
+	if cfg.IntroSlate == "" && cfg.SlateImage == "" {
+		return nil
+	}
+	if cfg.SlateDuration <= 0 {
+		return fmt.Errorf("-slate-duration must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits for -speed; beyond these the output is a handful of frames or
+// hours of a single one.
+const (
+	minSpeed = 0.01
+	maxSpeed = 100
+)
+
+// speedVideoFilter retimes the picture by factor (2 = twice as fast).
+func speedVideoFilter(factor float64) string {
+	return fmt.Sprintf("setpts=PTS/%g", factor)
+}
+
+// atempoChain retimes the sound by factor without changing its pitch.
+// atempo only takes 0.5-2.0 per instance, so larger changes are built from
+// several stages that multiply out to factor.
+func atempoChain(factor float64) string {
+	var stages []string
+	for factor > 2 {
+		stages = append(stages, "atempo=2.0")
+		factor /= 2
+	}
+	for factor < 0.5 {
+		stages = append(stages, "atempo=0.5")
+		factor /= 0.5
+	}
+	stages = append(stages, fmt.Sprintf("atempo=%g", factor))
+	return strings.Join(stages, ",")
+}
+
+func validateSpeed(factor float64) error {
+	if factor < minSpeed || factor > maxSpeed {
+		return fmt.Errorf("-speed must be between %g and %g, got %g", minSpeed, float64(maxSpeed), factor)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package mutecut
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// minSplitPiece drops slivers of sound (a click, a breath) between two
+// silences rather than writing them out as their own file.
+const minSplitPiece = 0.5
+
+// detectSilences returns every stretch of at least minGap seconds quieter
+// than noiseDB, on the input's timeline.
+func detectSilences(cfg Config, minGap, noiseDB float64) ([]Segment, error) {
+	args := []string{"-hide_banner", "-nostats"}
+	args = append(args, inputSourceArgs(cfg)...)
+	if cfg.AudioMap != "" {
+		args = append(args, "-map", cfg.AudioMap)
+	}
+	args = append(args,
+		"-vn",
+		"-af", fmt.Sprintf("silencedetect=noise=%.1fdB:d=%.3f", noiseDB, minGap),
+		"-f", "null", "-",
+	)
+	cmd := exec.CommandContext(jobContext(cfg), cfg.FfmpegBin, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("silence analysis failed: %w", err)
+	}
+
+	var silences []Segment
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if start, ok := firstMatch(silenceStartPattern, []byte(line)); ok {
+			silences = append(silences, Segment{Start: start, End: -1})
+		} else if end, ok := firstMatch(silenceEndPattern, []byte(line)); ok && len(silences) > 0 {
+			silences[len(silences)-1].End = end
+		}
+	}
+	return silences, nil
+}
+
+// soundBetween returns the pieces of [from, to] that aren't silent.
+func soundBetween(silences []Segment, from, to float64) []Segment {
+	var pieces []Segment
+	pos := from
+	for _, s := range silences {
+		end := s.End
+		if end < 0 {
+			end = to // Silent until the end of the input
+		}
+		if s.Start-pos >= minSplitPiece {
+			pieces = append(pieces, Segment{Start: pos, End: min(s.Start, to)})
+		}
+		if end > pos {
+			pos = end
+		}
+		if pos >= to {
+			break
+		}
+	}
+	if to-pos >= minSplitPiece {
+		pieces = append(pieces, Segment{Start: pos, End: to})
+	}
+	return pieces
+}
+
+// splitOnSilence writes each stretch of sound between silent gaps to its own
+// numbered file (name_001.ext, name_002.ext...), encoded the same way a
+// single output would be.
+func splitOnSilence(cfg Config) ([]string, error) {
+	silences, err := detectSilences(cfg, cfg.SilenceMin, cfg.SilenceNoise)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := 0.0, 0.0
+	if cfg.StartTime != "" {
+		from = toSeconds(cfg.StartTime)
+	}
+	if cfg.EndTime != "" {
+		to = toSeconds(cfg.EndTime)
+	} else {
+		duration, err := GetDuration(cfg)
+		if err != nil {
+			return nil, err
+		}
+		to = duration
+	}
+
+	pieces := soundBetween(silences, from, to)
+	if len(pieces) == 0 {
+		return nil, fmt.Errorf("no sound found between silences, nothing to split")
+	}
+	logf("Found %d silent gaps, writing %d pieces...\n", len(silences), len(pieces))
+
+	ext := filepath.Ext(cfg.OutputFile)
+	base := strings.TrimSuffix(cfg.OutputFile, ext)
+	if cfg.ExtractMP3 {
+		ext = audioFormats[cfg.AudioFormat].Ext
+	}
+
+	var outputs []string
+	for i, piece := range pieces {
+		pieceCfg := cfg
+		pieceCfg.StartTime = fmt.Sprintf("%.3f", piece.Start)
+		pieceCfg.EndTime = fmt.Sprintf("%.3f", piece.End)
+		pieceCfg.OutputFile = uniqueOutput(cfg, fmt.Sprintf("%s_%03d%s", base, i+1, ext))
+		pieceCfg.Stage, pieceCfg.Stages = i+1, len(pieces)
+		pieceCfg.ExpectedDuration = piece.End - piece.Start
+
+		logf("[%d/%d] %s -> %s: %s\n", i+1, len(pieces),
+			formatTimestamp(piece.Start), formatTimestamp(piece.End), pieceCfg.OutputFile)
+		if cfg.ExtractMP3 {
+			err = extractAudio(pieceCfg)
+		} else {
+			err = simpleCut(pieceCfg)
+		}
+		if err != nil {
+			return outputs, err
+		}
+		outputs = append(outputs, pieceCfg.OutputFile)
+	}
+	return outputs, nil
+}
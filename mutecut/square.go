@@ -0,0 +1,26 @@
+package mutecut
+
+import "fmt"
+
+// squareFilter returns the video filter that turns any aspect ratio into a
+// size x size square, either by cropping the centre or by letterboxing.
+func squareFilter(size int, mode, color string) string {
+	if mode == "pad" {
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=%s,setsar=1",
+			size, size, size, size, color)
+	}
+	return fmt.Sprintf("crop='min(iw,ih)':'min(iw,ih)',scale=%d:%d,setsar=1", size, size)
+}
+
+func validateSquare(cfg Config) error {
+	if cfg.SquareSize == 0 {
+		return nil
+	}
+	if cfg.SquareSize < 0 || cfg.SquareSize%2 != 0 {
+		return fmt.Errorf("-square must be a positive even number of pixels, got %d", cfg.SquareSize)
+	}
+	if cfg.SquareMode != "crop" && cfg.SquareMode != "pad" {
+		return fmt.Errorf("-square-mode must be 'crop' or 'pad', got '%s'", cfg.SquareMode)
+	}
+	return nil
+}
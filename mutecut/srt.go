@@ -0,0 +1,137 @@
+package mutecut
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// subtitleCue is one timed entry from an SRT file.
+type subtitleCue struct {
+	Index int
+	Start float64
+	End   float64
+	Text  string
+}
+
+var srtTimingPattern = regexp.MustCompile(`^(\d+:\d{2}:\d{2}[,.]\d+)\s*-->\s*(\d+:\d{2}:\d{2}[,.]\d+)`)
+
+// parseSRT reads the cues of an SRT subtitle file.
+func parseSRT(path string) ([]subtitleCue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cues []subtitleCue
+	var cur *subtitleCue
+	var text []string
+	flush := func() {
+		if cur != nil {
+			cur.Text = strings.Join(text, "\n")
+			cues = append(cues, *cur)
+		}
+		cur, text = nil, nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if m := srtTimingPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			cur = &subtitleCue{
+				Index: len(cues) + 1,
+				Start: parseSRTTime(m[1]),
+				End:   parseSRTTime(m[2]),
+			}
+			continue
+		}
+		if cur == nil {
+			continue // Cue number line (or junk before the first cue)
+		}
+		if line == "" {
+			flush()
+			continue
+		}
+		text = append(text, line)
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+// parseSRTTime parses "HH:MM:SS,mmm".
+func parseSRTTime(ts string) float64 {
+	return toSeconds(strings.Replace(ts, ",", ".", 1))
+}
+
+// maxSubtitleOffsetMs bounds -subtitle-offset; anything beyond an hour is
+// almost certainly a typo (seconds given as milliseconds the other way round).
+const maxSubtitleOffsetMs = 3600 * 1000
+
+// shiftCues moves every cue by offsetMs. Cues pushed entirely before zero are
+// dropped and ones straddling zero are cut to start at it.
+func shiftCues(cues []subtitleCue, offsetMs int) ([]subtitleCue, error) {
+	if offsetMs > maxSubtitleOffsetMs || offsetMs < -maxSubtitleOffsetMs {
+		return nil, fmt.Errorf("-subtitle-offset %dms is out of range (max ±%dms)", offsetMs, maxSubtitleOffsetMs)
+	}
+	if offsetMs == 0 {
+		return cues, nil
+	}
+	return offsetCues(cues, float64(offsetMs)/1000), nil
+}
+
+// offsetCues moves every cue by offset seconds, dropping or cutting them
+// at zero like shiftCues.
+func offsetCues(cues []subtitleCue, offset float64) []subtitleCue {
+	shifted := make([]subtitleCue, 0, len(cues))
+	for _, cue := range cues {
+		cue.Start += offset
+		cue.End += offset
+		if cue.End <= 0 {
+			continue
+		}
+		if cue.Start < 0 {
+			cue.Start = 0
+		}
+		shifted = append(shifted, cue)
+	}
+	return shifted
+}
+
+// muteSegmentsFromSubtitles returns a mute segment, widened by padding on
+// both sides, for every cue whose text matches pattern.
+func muteSegmentsFromSubtitles(path, pattern string, padding float64, offsetMs int) ([]Segment, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -mute-subtitle-regex: %w", err)
+	}
+	cues, err := parseSRT(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read subtitles: %w", err)
+	}
+	if cues, err = shiftCues(cues, offsetMs); err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for _, cue := range cues {
+		if !re.MatchString(cue.Text) {
+			continue
+		}
+		start := cue.Start - padding
+		if start < 0 {
+			start = 0
+		}
+		segments = append(segments, Segment{Start: start, End: cue.End + padding})
+		logf("Muting cue #%d [%s -> %s]: %s\n", cue.Index,
+			formatTimestamp(cue.Start), formatTimestamp(cue.End), strings.ReplaceAll(cue.Text, "\n", " / "))
+	}
+	return segments, nil
+}
@@ -0,0 +1,103 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var subsModes = map[string]bool{"soft": true, "burn": true}
+
+func validateSubs(cfg Config) error {
+	if cfg.Subs == "" {
+		return nil
+	}
+	if !subsModes[cfg.SubsMode] {
+		return fmt.Errorf("invalid -subs-mode '%s' (use soft or burn)", cfg.SubsMode)
+	}
+	info, err := os.Stat(cfg.Subs)
+	if err != nil {
+		return fmt.Errorf("cannot read subtitles: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("-subs '%s' is a directory", cfg.Subs)
+	}
+	switch {
+	case cfg.ExtractMP3, cfg.ExportWebP, cfg.ExportGIF, cfg.SplitSilence, cfg.Repair, len(cfg.ConcatFiles) > 0:
+		return fmt.Errorf("-subs only applies to a regular cut")
+	case cfg.SubsMode == "soft" && (cfg.RemoveStart != "" || cfg.TrimSilence || cfg.Speed != 1 ||
+		cfg.IntroSlate != "" || cfg.SlateImage != ""):
+		// Burned subtitles go through the same cuts as the picture; a separate
+		// stream would drift out of step
+		return fmt.Errorf("-subs-mode soft can't follow -remove-start, -trim-silence, -speed or a slate; use -subs-mode burn")
+	}
+	return nil
+}
+
+// prepareSubtitles writes a copy of the -subs file retimed to the output:
+// shifted by -subtitle-offset, moved back by -start and cut off at the end
+// of the trim window. It returns the copy's path, a temporary file.
+func prepareSubtitles(cfg Config, offsetMs int) (string, error) {
+	cues, err := parseSRT(cfg.Subs)
+	if err != nil {
+		return "", fmt.Errorf("cannot read subtitles: %w", err)
+	}
+	if cues, err = shiftCues(cues, offsetMs); err != nil {
+		return "", err
+	}
+	if cfg.StartTime != "" {
+		cues = offsetCues(cues, -toSeconds(cfg.StartTime))
+	}
+	if length := keptDuration(cfg); length > 0 {
+		kept := cues[:0]
+		for _, cue := range cues {
+			if cue.Start >= length {
+				continue
+			}
+			cue.End = min(cue.End, length)
+			kept = append(kept, cue)
+		}
+		cues = kept
+	}
+	if len(cues) == 0 {
+		warnln("Warning: no subtitle cues fall inside the output.")
+	}
+
+	f, err := os.CreateTemp("", "mutecut-*.srt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	for i, cue := range cues {
+		fmt.Fprintf(f, "%d\n%s --> %s\n%s\n\n", i+1, srtTimestamp(cue.Start), srtTimestamp(cue.End), cue.Text)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// srtTimestamp renders seconds as SRT's HH:MM:SS,mmm.
+func srtTimestamp(sec float64) string {
+	return strings.Replace(formatTimestamp(sec), ".", ",", 1)
+}
+
+// subtitleCodec picks a subtitle codec the output container can hold.
+func subtitleCodec(output string) string {
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".mkv":
+		return "srt"
+	case ".webm":
+		return "webvtt"
+	}
+	return "mov_text"
+}
+
+// burnSubtitlesFilter renders path into the picture. The path is escaped
+// for the filter graph, so Windows drive letters survive.
+func burnSubtitlesFilter(path string) string {
+	escaped := strings.NewReplacer(":", `\:`, "'", `\'`).Replace(filepath.ToSlash(path))
+	return fmt.Sprintf("subtitles=filename='%s'", escaped)
+}
@@ -0,0 +1,68 @@
+package mutecut
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// syncProbeSeconds limits how much of the input -auto-sync analyses; the
+// lead-in is all it looks at.
+const syncProbeSeconds = "120"
+
+var (
+	blackEndPattern     = regexp.MustCompile(`black_end:\s*([0-9.]+)`)
+	silenceStartPattern = regexp.MustCompile(`silence_start:\s*(-?[0-9.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end:\s*([0-9.]+)`)
+)
+
+// detectAudioDelay estimates a constant A/V offset by lining up where the
+// picture first comes out of black with where the sound first comes out of
+// silence. The result is the -audio-delay that would correct it: negative
+// when the audio arrives late. It's a heuristic and only works for inputs
+// that start with a black/silent lead-in.
+func detectAudioDelay(cfg Config) (float64, error) {
+	args := []string{"-hide_banner", "-nostats", "-t", syncProbeSeconds}
+	args = append(args, inputSourceArgs(cfg)...)
+	args = append(args,
+		"-vf", "blackdetect=d=0.05:pix_th=0.10",
+		"-af", "silencedetect=noise=-40dB:d=0.05",
+		"-f", "null", "-",
+	)
+	cmd := exec.CommandContext(jobContext(cfg), cfg.FfmpegBin, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("sync analysis failed: %w", err)
+	}
+
+	videoStart, ok := firstMatch(blackEndPattern, stderr.Bytes())
+	if !ok {
+		return 0, fmt.Errorf("no black lead-in found to align the video on")
+	}
+	audioStart, ok := firstMatch(silenceEndPattern, stderr.Bytes())
+	if !ok {
+		return 0, fmt.Errorf("no silent lead-in found to align the audio on")
+	}
+	return videoStart - audioStart, nil
+}
+
+func firstMatch(re *regexp.Regexp, out []byte) (float64, bool) {
+	m := re.FindSubmatch(out)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(m[1]), 64)
+	return v, err == nil
+}
+
+// mapOnInput re-targets a "0:N" stream specifier at another input index.
+func mapOnInput(spec string, input int) string {
+	if rest, ok := strings.CutPrefix(spec, "0:"); ok {
+		return fmt.Sprintf("%d:%s", input, rest)
+	}
+	return spec
+}
@@ -0,0 +1,50 @@
+package mutecut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Built-in lavfi sources accepted as -i, so features can be tried out (and
+// bugs reproduced) without a real video file.
+const (
+	testVideoSource = "testsrc"
+	testAudioSource = "sine"
+)
+
+func isTestSource(input string) bool {
+	return input == testVideoSource || input == testAudioSource
+}
+
+// testSourceGraph returns the lavfi graph for a test input. testsrc comes
+// with a sine tone on a second output so both audio and video paths are
+// exercised.
+func testSourceGraph(input string, duration float64) string {
+	d := fmt.Sprintf("%.3f", duration)
+	if input == testAudioSource {
+		return "sine=frequency=1000:sample_rate=48000:duration=" + d
+	}
+	return strings.Join([]string{
+		"testsrc=size=1280x720:rate=30:duration=" + d + "[out0]",
+		"sine=frequency=1000:sample_rate=48000:duration=" + d + "[out1]",
+	}, ";")
+}
+
+// inputSourceArgs returns the ffmpeg arguments that open cfg.InputFile.
+func inputSourceArgs(cfg Config) []string {
+	if isTestSource(cfg.InputFile) {
+		return []string{"-f", "lavfi", "-i", testSourceGraph(cfg.InputFile, cfg.TestDuration)}
+	}
+	if isCaptureInput(cfg.InputFile) {
+		return captureInputArgs(cfg.InputFile)
+	}
+	return []string{"-i", cfg.InputFile}
+}
+
+// probeSourceArgs is the ffprobe equivalent of inputSourceArgs.
+func probeSourceArgs(cfg Config) []string {
+	if isTestSource(cfg.InputFile) {
+		return []string{"-f", "lavfi", testSourceGraph(cfg.InputFile, cfg.TestDuration)}
+	}
+	return []string{cfg.InputFile}
+}
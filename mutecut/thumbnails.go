@@ -0,0 +1,88 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// thumbnailTimes returns when to grab frames between from and to: count
+// evenly spaced frames (each in the middle of its share of the clip), or
+// one every interval seconds when interval is set.
+func thumbnailTimes(from, to float64, count int, interval float64) []float64 {
+	var times []float64
+	if interval > 0 {
+		for t := from; t < to; t += interval {
+			times = append(times, t)
+		}
+		return times
+	}
+	step := (to - from) / float64(count)
+	for i := 0; i < count; i++ {
+		times = append(times, from+(float64(i)+0.5)*step)
+	}
+	return times
+}
+
+// thumbnailDir is the folder the thumbnails go in: "<input>_thumbs", next
+// to the input unless -output-dir says otherwise.
+func thumbnailDir(input, outputDir string) string {
+	dir := strings.TrimSuffix(input, filepath.Ext(input)) + "_thumbs"
+	if outputDir != "" {
+		dir = filepath.Join(outputDir, filepath.Base(dir))
+	}
+	return dir
+}
+
+// writeThumbnails saves JPEG frames from the input (or the -start/-end
+// range of it) into dir, named by position and timestamp so they double as
+// a guide to cut points.
+func writeThumbnails(cfg Config, dir string, count int, interval float64, width int) error {
+	if count <= 0 && interval <= 0 {
+		return fmt.Errorf("-thumbnails must be greater than 0")
+	}
+	if width < 0 {
+		return fmt.Errorf("-thumb-width cannot be negative")
+	}
+
+	from, to := 0.0, 0.0
+	if cfg.StartTime != "" {
+		from = toSeconds(cfg.StartTime)
+	}
+	if cfg.EndTime != "" {
+		to = toSeconds(cfg.EndTime)
+	} else {
+		duration, err := GetDuration(cfg)
+		if err != nil {
+			return err
+		}
+		to = duration
+	}
+	if to <= from {
+		return fmt.Errorf("nothing to take thumbnails of between %s and %s", formatTimestamp(from), formatTimestamp(to))
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	times := thumbnailTimes(from, to, count, interval)
+	logf("Writing %d thumbnails to: %s\n", len(times), dir)
+
+	for i, t := range times {
+		name := fmt.Sprintf("%03d_%s.jpg", i+1, strings.ReplaceAll(formatTimestamp(t), ":", "-"))
+		file := uniqueOutput(cfg, filepath.Join(dir, name))
+		logf("[%d/%d] %s\n", i+1, len(times), file)
+
+		args := append([]string{"-loglevel", "error", "-ss", strconv.FormatFloat(t, 'f', 3, 64)}, inputSourceArgs(cfg)...)
+		args = append(args, "-frames:v", "1", "-q:v", "2")
+		if width > 0 {
+			args = append(args, "-vf", fmt.Sprintf("scale=%d:-2", width))
+		}
+		if err := runFFmpeg(cfg, append(args, "-y", file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package mutecut
+
+import "fmt"
+
+// applySkipIntroOutro shifts the trim window inward by the -skip-intro and
+// -skip-outro durations. They stack on top of any explicit -start/-end, so
+// "-start 10 -skip-intro 5" starts at 15s.
+func applySkipIntroOutro(cfg *Config) error {
+	if cfg.SkipIntro == "" && cfg.SkipOutro == "" {
+		return nil
+	}
+
+	start := 0.0
+	if cfg.StartTime != "" {
+		start = toSeconds(cfg.StartTime)
+	}
+	if cfg.SkipIntro != "" {
+		start += toSeconds(cfg.SkipIntro)
+	}
+	cfg.StartTime = fmt.Sprintf("%.3f", start)
+
+	if cfg.SkipOutro != "" {
+		var end float64
+		if cfg.EndTime != "" {
+			end = toSeconds(cfg.EndTime)
+		} else {
+			// Without an explicit end we need the real length to count back from
+			duration, err := GetDuration(*cfg)
+			if err != nil {
+				return err
+			}
+			end = duration
+		}
+		end -= toSeconds(cfg.SkipOutro)
+		cfg.EndTime = fmt.Sprintf("%.3f", end)
+	}
+
+	if cfg.EndTime != "" {
+		if end := toSeconds(cfg.EndTime); end <= start {
+			return fmt.Errorf("skipping intro/outro leaves nothing to keep (%.3fs -> %.3fs)", start, end)
+		}
+		logf("Trim window: %ss -> %ss\n", cfg.StartTime, cfg.EndTime)
+	} else {
+		logf("Trim window: %ss -> end\n", cfg.StartTime)
+	}
+	return nil
+}
+
+// clampTimes pulls -start/-end back inside the input's real length instead
+// of letting ffmpeg fail or produce an empty file. Each adjustment is logged.
+func clampTimes(cfg *Config) error {
+	if cfg.StartTime == "" && cfg.EndTime == "" {
+		return nil
+	}
+
+	duration, err := GetDuration(*cfg)
+	if err != nil {
+		return err
+	}
+
+	if cfg.StartTime != "" {
+		start := toSeconds(cfg.StartTime)
+		if start < 0 {
+			warnf("Warning: -start %s is before the beginning, clamped to 0\n", cfg.StartTime)
+			cfg.StartTime = "0"
+		} else if start >= duration {
+			return fmt.Errorf("-start %s is past the end of the input (%.3fs)", cfg.StartTime, duration)
+		}
+	}
+
+	if cfg.EndTime != "" {
+		end := toSeconds(cfg.EndTime)
+		if end > duration {
+			warnf("Warning: -end %s is past the end of the input, clamped to %.3f\n", cfg.EndTime, duration)
+			cfg.EndTime = fmt.Sprintf("%.3f", duration)
+		} else if end <= 0 {
+			return fmt.Errorf("-end %s leaves nothing to keep", cfg.EndTime)
+		}
+	}
+
+	if cfg.StartTime != "" && cfg.EndTime != "" {
+		start := toSeconds(cfg.StartTime)
+		end := toSeconds(cfg.EndTime)
+		if start >= end {
+			return fmt.Errorf("-start %s is not before -end %s", cfg.StartTime, cfg.EndTime)
+		}
+	}
+	return nil
+}
+
+// expectedOutputDuration works out how long the output will be from the
+// trim window, falling back to probing the input. It returns 0 if unknown.
+func expectedOutputDuration(cfg Config) float64 {
+	d := keptDuration(cfg) - removedSeconds(cfg) - silenceCutSeconds(cfg)
+	if d <= 0 {
+		return 0
+	}
+	if cfg.IntroSlate != "" || cfg.SlateImage != "" {
+		d += cfg.SlateDuration
+	}
+	if cfg.Speed > 0 {
+		d /= cfg.Speed
+	}
+	return d
+}
+
+// keptDuration is the length selected by the trim flags.
+func keptDuration(cfg Config) float64 {
+	start := 0.0
+	if cfg.StartTime != "" {
+		start = toSeconds(cfg.StartTime)
+	}
+	if cfg.EndTime != "" {
+		if end := toSeconds(cfg.EndTime); end > start {
+			return end - start
+		}
+		return 0
+	}
+	if cfg.Duration != "" {
+		return toSeconds(cfg.Duration)
+	}
+	if isCaptureInput(cfg.InputFile) {
+		return 0
+	}
+	duration, err := GetDuration(cfg)
+	if err != nil || duration <= start {
+		return 0
+	}
+	return duration - start
+}
+
+// checkMaxLen rejects jobs whose output would run past -max-len, before any
+// encoding starts.
+func checkMaxLen(cfg Config) error {
+	if cfg.MaxVideoLen <= 0 {
+		return nil
+	}
+	length := expectedOutputDuration(cfg)
+	if length <= 0 {
+		return fmt.Errorf("cannot work out the output length to check -max-len against")
+	}
+	if length > cfg.MaxVideoLen {
+		return fmt.Errorf("output would be %s long, over the -max-len of %s",
+			formatTimestamp(length), formatTimestamp(cfg.MaxVideoLen))
+	}
+	return nil
+}
+
+// windowSegments moves segments given on the input's timeline onto the
+// output's: shifted back by -start, and clipped to the -end/-duration window
+// when there is one.
+func windowSegments(cfg Config, segments []Segment) []Segment {
+	offset := 0.0
+	if cfg.StartTime != "" {
+		offset = toSeconds(cfg.StartTime)
+	}
+	segments = rebaseSegments(segments, offset)
+
+	length := 0.0
+	switch {
+	case cfg.EndTime != "":
+		length = toSeconds(cfg.EndTime) - offset
+	case cfg.Duration != "":
+		length = toSeconds(cfg.Duration)
+	}
+	if length <= 0 {
+		return segments
+	}
+	clipped := make([]Segment, 0, len(segments))
+	for _, seg := range segments {
+		if seg.Start >= length {
+			continue
+		}
+		seg.End = min(seg.End, length)
+		clipped = append(clipped, seg)
+	}
+	return clipped
+}
+
+// rebaseSegments shifts segments back by offset seconds, for when the input
+// has been seeked to offset. Segments that end before it are dropped and
+// ones that straddle it start at zero.
+func rebaseSegments(segments []Segment, offset float64) []Segment {
+	if offset <= 0 {
+		return segments
+	}
+	rebased := make([]Segment, 0, len(segments))
+	for _, seg := range segments {
+		seg.Start -= offset
+		seg.End -= offset
+		if seg.End <= 0 {
+			continue
+		}
+		seg.Start = max(seg.Start, 0)
+		rebased = append(rebased, seg)
+	}
+	return rebased
+}
@@ -0,0 +1,92 @@
+package mutecut
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWindowSegments(t *testing.T) {
+	cfg := Config{StartTime: "60", EndTime: "90"}
+	got := windowSegments(cfg, []Segment{
+		{30, 40},  // before the window: dropped
+		{55, 65},  // straddles -start: starts at 0
+		{70, 75},  // inside: shifted back by 60
+		{85, 100}, // straddles -end: stops at 30
+		{95, 99},  // after the window: dropped
+	})
+	want := []Segment{{0, 5}, {10, 15}, {25, 30}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windowSegments = %v, want %v", got, want)
+	}
+
+	cfg = Config{StartTime: "1:00", Duration: "10"}
+	got = windowSegments(cfg, []Segment{{65, 80}})
+	if want := []Segment{{5, 10}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("windowSegments with -duration = %v, want %v", got, want)
+	}
+}
+
+func TestSimpleCutArgsRebasesMuteAndVolume(t *testing.T) {
+	cfg := Config{
+		InputFile:  "in.mp4",
+		OutputFile: "out.mp4",
+		StartTime:  "00:01:00",
+		EndTime:    "00:01:30",
+		MuteStart:  "00:01:10",
+		MuteEnd:    "00:01:15",
+		VolStart:   "00:00:55",
+		VolEnd:     "00:01:05",
+		VolLevel:   0.5,
+		Speed:      1,
+	}
+	args := strings.Join(simpleCutArgs(cfg), " ")
+	for _, want := range []string{
+		"volume=0:enable='between(t,10.000,15.000)'",
+		"volume=0.5:enable='between(t,0.000,5.000)'",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("simpleCutArgs missing %q in:\n%s", want, args)
+		}
+	}
+}
+
+func TestSimpleCutArgsRebasesReplace(t *testing.T) {
+	cfg := Config{
+		InputFile:    "in.mp4",
+		OutputFile:   "out.mp4",
+		StartTime:    "60",
+		ReplaceStart: "55",
+		ReplaceEnd:   "70",
+		ReplaceWith:  "black",
+		Speed:        1,
+	}
+	args := strings.Join(simpleCutArgs(cfg), " ")
+	for _, want := range []string{
+		"setpts=PTS-STARTPTS-5.000/TB",
+		"overlay=eof_action=pass:enable='between(t,0.000,10.000)'",
+		"volume=0:enable='between(t,0.000,10.000)'",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("simpleCutArgs missing %q in:\n%s", want, args)
+		}
+	}
+}
+
+func TestSimpleCutArgsRebasesDucking(t *testing.T) {
+	cfg := Config{
+		InputFile:  "in.mp4",
+		OutputFile: "out.mp4",
+		StartTime:  "60",
+		DuckRanges: []DuckRange{
+			{Segment{30, 40}, 0.2}, // Before -start: dropped
+			{Segment{70, 80}, 0.3},
+		},
+		Speed: 1,
+	}
+	args := strings.Join(simpleCutArgs(cfg), " ")
+	want := "volume='if(between(t,10.000,20.000),0.3,1)':eval=frame"
+	if !strings.Contains(args, want) {
+		t.Errorf("simpleCutArgs missing %q in:\n%s", want, args)
+	}
+}
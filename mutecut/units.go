@@ -0,0 +1,70 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// checkTimeUnits looks for time flags that were probably written in the
+// wrong units, judged against the input's duration:
+//
+//   - a bare number past the end that fits once read as MMSS, e.g.
+//     "-mute-start 600" meaning 6:00 rather than 600 seconds;
+//   - an MM:SS value that is tiny next to a long input but fits once read
+//     as HH:MM, e.g. "1:30" on a three-hour recording.
+//
+// It only returns warnings; the values are used as given.
+func checkTimeUnits(cfg Config, duration float64) []string {
+	if duration <= 0 {
+		return nil
+	}
+
+	var warnings []string
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"-start", cfg.StartTime},
+		{"-end", cfg.EndTime},
+		{"-mute-start", cfg.MuteStart},
+		{"-mute-end", cfg.MuteEnd},
+	} {
+		if f.value == "" {
+			continue
+		}
+		seconds := toSeconds(f.value)
+
+		switch strings.Count(f.value, ":") {
+		case 0:
+			if seconds <= duration || seconds < 100 {
+				continue
+			}
+			msg := fmt.Sprintf("%s %s is read as %s, past the end of the input (%s)",
+				f.name, f.value, formatTimestamp(seconds), formatTimestamp(duration))
+			if alt, ok := readAsMMSS(f.value); ok && alt <= duration {
+				msg += fmt.Sprintf("; did you mean %s?", formatTimestamp(alt))
+			}
+			warnings = append(warnings, msg)
+		case 1:
+			asHHMM := seconds * 60
+			if duration < 3600 || seconds >= duration/100 || asHHMM > duration {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"%s %s is read as MM:SS (%s) on a %s input; for %s write %s:00",
+				f.name, f.value, formatTimestamp(seconds), formatTimestamp(duration),
+				formatTimestamp(asHHMM), f.value))
+		}
+	}
+	return warnings
+}
+
+// readAsMMSS reinterprets a bare integer such as "600" as 6:00.
+func readAsMMSS(value string) (float64, bool) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 100 || n%100 >= 60 {
+		return 0, false
+	}
+	return float64(n/100*60 + n%100), true
+}
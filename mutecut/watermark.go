@@ -0,0 +1,58 @@
+package mutecut
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// watermarkPositions maps -watermark-pos to overlay x/y expressions; {m} is
+// replaced by the margin in pixels.
+var watermarkPositions = map[string]string{
+	"tl":     "x={m}:y={m}",
+	"tr":     "x=W-w-{m}:y={m}",
+	"bl":     "x={m}:y=H-h-{m}",
+	"br":     "x=W-w-{m}:y=H-h-{m}",
+	"center": "x=(W-w)/2:y=(H-h)/2",
+}
+
+func validateWatermark(cfg Config) error {
+	if cfg.Watermark == "" {
+		return nil
+	}
+	if _, err := os.Stat(cfg.Watermark); err != nil {
+		return fmt.Errorf("cannot read watermark: %w", err)
+	}
+	if _, ok := watermarkPositions[cfg.WatermarkPos]; !ok {
+		return fmt.Errorf("invalid -watermark-pos '%s' (use tl, tr, bl, br or center)", cfg.WatermarkPos)
+	}
+	if cfg.WatermarkMargin < 0 {
+		return fmt.Errorf("-watermark-margin cannot be negative")
+	}
+	if cfg.WatermarkOpacity <= 0 || cfg.WatermarkOpacity > 1 {
+		return fmt.Errorf("-watermark-opacity must be above 0 and at most 1")
+	}
+	if cfg.ExtractMP3 || cfg.ExportWebP || cfg.ExportGIF || cfg.Repair || len(cfg.ConcatFiles) > 0 {
+		return fmt.Errorf("-watermark only applies to a regular cut")
+	}
+	return nil
+}
+
+// watermarkGraph overlays the image on input over src, after running the
+// plain video filters in pre (if any) on src.
+func watermarkGraph(cfg Config, src, pre string, input int, out string) string {
+	base := src
+	graph := ""
+	if pre != "" {
+		graph = src + pre + "[wmbase];"
+		base = "[wmbase]"
+	}
+	logo := fmt.Sprintf("[%d:v]", input)
+	if cfg.WatermarkOpacity < 1 {
+		graph += fmt.Sprintf("%sformat=rgba,colorchannelmixer=aa=%g[wm];", logo, cfg.WatermarkOpacity)
+		logo = "[wm]"
+	}
+	pos := strings.ReplaceAll(watermarkPositions[cfg.WatermarkPos], "{m}", strconv.Itoa(cfg.WatermarkMargin))
+	return graph + fmt.Sprintf("%s%soverlay=%s%s", base, logo, pos, out)
+}
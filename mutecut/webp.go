@@ -0,0 +1,42 @@
+package mutecut
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// exportWebP renders the (trimmed) input as an animated WebP, which is
+// usually smaller and better looking than the same clip as a GIF.
+func exportWebP(cfg Config) error {
+	if !hasEncoder(cfg, "libwebp_anim") {
+		return fmt.Errorf("this ffmpeg build has no libwebp_anim encoder, cannot export WebP")
+	}
+
+	logf("Exporting animated WebP to: %s\n", cfg.OutputFile)
+
+	args := append(getInputArgs(cfg),
+		"-vf", fmt.Sprintf("fps=%d,scale=%d:-1:flags=lanczos", cfg.WebPFPS, cfg.WebPWidth),
+		"-an",
+		"-c:v", "libwebp_anim",
+		"-quality", strconv.Itoa(cfg.WebPQuality),
+		"-loop", strconv.Itoa(cfg.WebPLoop),
+		"-y", cfg.OutputFile,
+	)
+	return runFFmpeg(cfg, args)
+}
+
+func validateWebP(cfg Config) error {
+	if cfg.WebPFPS <= 0 {
+		return fmt.Errorf("-webp-fps must be greater than 0")
+	}
+	if cfg.WebPWidth <= 0 {
+		return fmt.Errorf("-webp-width must be greater than 0")
+	}
+	if cfg.WebPQuality < 0 || cfg.WebPQuality > 100 {
+		return fmt.Errorf("-webp-quality must be between 0 and 100")
+	}
+	if cfg.WebPLoop < 0 {
+		return fmt.Errorf("-webp-loop must be 0 (forever) or a positive count")
+	}
+	return nil
+}
@@ -0,0 +1,400 @@
+package mutecut
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kkdai/youtube/v2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// downloadOptions are the settings shared by every YouTube download.
+type downloadOptions struct {
+	CheckSpace  bool
+	MaxDuration float64 // Longest video accepted, in seconds; 0 means no cap
+	Quality     string  // -yt-quality: "best", "worst" or a height like "720p"
+	Verbose     bool    // Log progress line by line instead of redrawing it
+	AudioOnly   bool    // Fetch just the best audio stream, for audio extraction
+
+	Ctx context.Context // Cancels the download; nil means never
+}
+
+// context returns o.Ctx, or a context that is never cancelled.
+func (o downloadOptions) context() context.Context {
+	if o.Ctx != nil {
+		return o.Ctx
+	}
+	return context.Background()
+}
+
+// downloadYoutubeVideo downloads url to a file named after the video's title.
+func downloadYoutubeVideo(url string, opts downloadOptions) (string, error) {
+	logln("Initializing YouTube client...")
+	client := youtube.Client{}
+
+	logf("Fetching video info for: %s\n", url)
+	video, err := client.GetVideoContext(opts.context(), url)
+	if err != nil {
+		return "", fmt.Errorf("failed to get video info: %w", err)
+	}
+
+	return saveYoutubeVideo(&client, video, url, ".", opts)
+}
+
+// saveYoutubeVideo downloads an already-fetched video into dir, named
+// after its title.
+func saveYoutubeVideo(client *youtube.Client, video *youtube.Video, url, dir string, opts downloadOptions) (string, error) {
+	logf("Found video: %s (%s)\n", video.Title, video.Duration)
+	if opts.MaxDuration > 0 && video.Duration.Seconds() > opts.MaxDuration {
+		return "", fmt.Errorf("video is %s long, over the -yt-max-duration cap of %s", video.Duration, formatTimestamp(opts.MaxDuration))
+	}
+
+	if opts.AudioOnly {
+		return saveYoutubeAudio(client, video, url, dir, opts)
+	}
+
+	target, err := parseYTQuality(opts.Quality)
+	if err != nil {
+		return "", err
+	}
+
+	// Muxed formats carry audio and video in one stream but top out around
+	// 720p; taller ones only come as separate video and audio streams.
+	muxed := pickFormat(video.Formats.WithAudioChannels().Select(isVideoFormat), target)
+	videoOnly, audioOnly := pickAdaptive(video.Formats, target)
+	adaptive := videoOnly != nil && audioOnly != nil &&
+		(muxed == nil || betterFit(videoOnly.Height, muxed.Height, target))
+	if muxed == nil && !adaptive {
+		return "", fmt.Errorf("no suitable video format with audio found")
+	}
+
+	chosen := muxed
+	if adaptive {
+		chosen = videoOnly
+	}
+	if target != math.MaxInt && target != 0 && chosen.Height != target {
+		logf("No %s download available, using the closest: %dp\n", opts.Quality, chosen.Height)
+	}
+
+	// Sanitize filename
+	cleanTitle := sanitizeFilename(video.Title)
+	outputFile := filepath.Join(dir, cleanTitle+".mp4")
+	// Ensure unique filename
+	outputFile = ensureUniqueFilename(outputFile)
+
+	if adaptive {
+		if err := downloadAdaptive(client, video, videoOnly, audioOnly, url, outputFile, opts); err != nil {
+			return "", err
+		}
+		return outputFile, nil
+	}
+
+	logf("Downloading format: %s (Quality: %s, %dx%d)\n", muxed.MimeType, muxed.QualityLabel, muxed.Width, muxed.Height)
+	if opts.CheckSpace && muxed.ContentLength > 0 {
+		if err := ensureDiskSpace(outputFile, muxed.ContentLength); err != nil {
+			return "", err
+		}
+	}
+
+	logf("Downloading to: %s\n", outputFile)
+	if err := downloadWithResume(client, video, muxed, url, outputFile, opts); err != nil {
+		return "", err
+	}
+
+	return outputFile, nil
+}
+
+// saveYoutubeAudio downloads only the best audio stream of a video into dir,
+// for when the video would be thrown away by audio extraction anyway.
+func saveYoutubeAudio(client *youtube.Client, video *youtube.Video, url, dir string, opts downloadOptions) (string, error) {
+	audio := bestAudio(video.Formats.Select(func(f youtube.Format) bool {
+		return strings.HasPrefix(f.MimeType, "audio/")
+	}))
+	if audio == nil {
+		return "", fmt.Errorf("no audio-only format found")
+	}
+
+	ext := ".m4a"
+	if strings.HasPrefix(audio.MimeType, "audio/webm") {
+		ext = ".webm"
+	}
+	outputFile := ensureUniqueFilename(filepath.Join(dir, sanitizeFilename(video.Title)+ext))
+
+	logf("Downloading audio only: %s (%d kb/s)\n", audio.MimeType, audio.Bitrate/1000)
+	if opts.CheckSpace && audio.ContentLength > 0 {
+		if err := ensureDiskSpace(outputFile, audio.ContentLength); err != nil {
+			return "", err
+		}
+	}
+
+	logf("Downloading to: %s\n", outputFile)
+	if err := downloadWithResume(client, video, audio, url, outputFile, opts); err != nil {
+		return "", err
+	}
+	return outputFile, nil
+}
+
+// isPlaylistURL reports whether url points at a whole playlist rather than
+// a video. A watch URL that merely came from a playlist (it has v= as well
+// as list=) counts as a single video.
+func isPlaylistURL(url string) bool {
+	return strings.Contains(url, "list=") && !strings.Contains(url, "v=")
+}
+
+// downloadYoutubePlaylist downloads every video in a playlist into a folder
+// named after it. A failed video is reported and skipped; the error
+// returned lists how many failed.
+func downloadYoutubePlaylist(url string, opts downloadOptions) error {
+	client := youtube.Client{}
+
+	logf("Fetching playlist: %s\n", url)
+	playlist, err := client.GetPlaylistContext(opts.context(), url)
+	if err != nil {
+		return fmt.Errorf("failed to get playlist: %w", err)
+	}
+	if len(playlist.Videos) == 0 {
+		return fmt.Errorf("playlist '%s' has no videos", playlist.Title)
+	}
+
+	dir := sanitizeFilename(playlist.Title)
+	if dir == "" {
+		dir = playlist.ID
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	logf("Downloading %d videos from '%s' into: %s\n", len(playlist.Videos), playlist.Title, dir)
+
+	var failed []string
+	for i, entry := range playlist.Videos {
+		if err := opts.context().Err(); err != nil {
+			return fmt.Errorf("playlist download stopped: %w", err)
+		}
+		logf("\n[%d/%d] %s\n", i+1, len(playlist.Videos), entry.Title)
+		video, err := client.VideoFromPlaylistEntryContext(opts.context(), entry)
+		if err == nil {
+			videoURL := "https://www.youtube.com/watch?v=" + entry.ID
+			_, err = saveYoutubeVideo(&client, video, videoURL, dir, opts)
+		}
+		if err != nil {
+			errorf("Failed: %v\n", err)
+			failed = append(failed, entry.Title)
+		}
+	}
+
+	logf("\nDownloaded %d of %d videos to: %s\n", len(playlist.Videos)-len(failed), len(playlist.Videos), dir)
+	if len(failed) > 0 {
+		for _, title := range failed {
+			errorf("  failed: %s\n", title)
+		}
+		return fmt.Errorf("%d of %d videos failed to download", len(failed), len(playlist.Videos))
+	}
+	return nil
+}
+
+func isVideoFormat(f youtube.Format) bool {
+	return f.Height > 0
+}
+
+// betterFit reports whether height a suits the -yt-quality target better
+// than b: the tallest at or below the target wins, and if both are above it
+// the shorter one does.
+func betterFit(a, b, target int) bool {
+	switch {
+	case a <= target && b <= target:
+		return a > b
+	case a <= target:
+		return true
+	case b <= target:
+		return false
+	}
+	return a < b
+}
+
+// pickFormat chooses the format from formats whose height best fits target,
+// preferring the higher bitrate between equal heights. It returns nil if
+// formats is empty.
+func pickFormat(formats youtube.FormatList, target int) *youtube.Format {
+	var best *youtube.Format
+	for i := range formats {
+		f := &formats[i]
+		if best == nil || betterFit(f.Height, best.Height, target) ||
+			(f.Height == best.Height && f.Bitrate > best.Bitrate) {
+			best = f
+		}
+	}
+	return best
+}
+
+// pickAdaptive chooses a video-only stream for target and the best
+// audio-only stream to go with it. Only MP4/M4A streams are considered so
+// they can be muxed into .mp4 without re-encoding.
+func pickAdaptive(formats youtube.FormatList, target int) (*youtube.Format, *youtube.Format) {
+	videoOnly := formats.Select(func(f youtube.Format) bool {
+		return f.AudioChannels == 0 && f.Height > 0 && strings.HasPrefix(f.MimeType, "video/mp4")
+	})
+	audioOnly := formats.Select(func(f youtube.Format) bool {
+		return strings.HasPrefix(f.MimeType, "audio/mp4")
+	})
+	return pickFormat(videoOnly, target), bestAudio(audioOnly)
+}
+
+// bestAudio returns the highest-bitrate format in formats, or nil.
+func bestAudio(formats youtube.FormatList) *youtube.Format {
+	var best *youtube.Format
+	for i := range formats {
+		if best == nil || formats[i].Bitrate > best.Bitrate {
+			best = &formats[i]
+		}
+	}
+	return best
+}
+
+// downloadAdaptive downloads separate video and audio streams beside
+// outputFile and muxes them into it without re-encoding. The pieces are
+// deleted once muxed; if anything fails they're kept so a rerun resumes.
+func downloadAdaptive(client *youtube.Client, video *youtube.Video, videoFormat, audioFormat *youtube.Format, url, outputFile string, opts downloadOptions) error {
+	logf("Downloading video %s (%dx%d) and audio (%d kb/s) separately\n",
+		videoFormat.QualityLabel, videoFormat.Width, videoFormat.Height, audioFormat.Bitrate/1000)
+
+	if opts.CheckSpace {
+		// The pieces and the muxed copy exist side by side until cleanup
+		needed := 2 * (videoFormat.ContentLength + audioFormat.ContentLength)
+		if needed > 0 {
+			if err := ensureDiskSpace(outputFile, needed); err != nil {
+				return err
+			}
+		}
+	}
+
+	ffmpegBin := resolveBinary("ffmpeg")
+	if ffmpegBin == "" {
+		return fmt.Errorf("ffmpeg is needed to combine the video and audio streams but was not found")
+	}
+
+	base := strings.TrimSuffix(outputFile, filepath.Ext(outputFile))
+	videoFile := fmt.Sprintf("%s.f%d.mp4", base, videoFormat.ItagNo)
+	audioFile := fmt.Sprintf("%s.f%d.m4a", base, audioFormat.ItagNo)
+
+	pieces := []struct {
+		kind   string
+		format *youtube.Format
+		file   string
+	}{
+		{"video", videoFormat, videoFile},
+		{"audio", audioFormat, audioFile},
+	}
+	for _, p := range pieces {
+		if finishedDownload(p.file, p.format) {
+			logf("Already downloaded %s: %s\n", p.kind, p.file)
+			continue
+		}
+		logf("Downloading %s to: %s\n", p.kind, p.file)
+		if err := downloadWithResume(client, video, p.format, url, p.file, opts); err != nil {
+			return err
+		}
+	}
+
+	logf("Combining into: %s\n", outputFile)
+	cmd := exec.CommandContext(opts.context(), ffmpegBin, "-hide_banner", "-loglevel", "error",
+		"-i", videoFile, "-i", audioFile,
+		"-map", "0:v", "-map", "1:a", "-c", "copy", "-movflags", "+faststart",
+		"-y", outputFile,
+	)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputFile)
+		return fmt.Errorf("combining video and audio failed: %w", err)
+	}
+	os.Remove(videoFile)
+	os.Remove(audioFile)
+	return nil
+}
+
+// parseYTQuality turns a -yt-quality value into a target height. "best"
+// means as tall as possible and "worst" the smallest available.
+func parseYTQuality(quality string) (int, error) {
+	switch strings.ToLower(quality) {
+	case "", "best":
+		return math.MaxInt, nil
+	case "worst":
+		return 0, nil
+	}
+	height, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(quality), "p"))
+	if err != nil || height <= 0 {
+		return 0, fmt.Errorf("invalid -yt-quality '%s' (use best, worst or a height like 720p)", quality)
+	}
+	return height, nil
+}
+
+func sanitizeFilename(name string) string {
+	// Remove invalid characters
+	re := regexp.MustCompile(`[<>:"/\\|?*]`)
+	return re.ReplaceAllString(name, "_")
+}
+
+// slugReplacements covers letters that don't decompose into ASCII + accent.
+var slugReplacements = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "Æ", "AE", "ø", "o", "Ø", "O",
+	"œ", "oe", "Œ", "OE", "đ", "d", "Đ", "D", "ł", "l", "Ł", "L",
+)
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a name into a URL-safe slug: accents are stripped where
+// possible, everything else collapses into single hyphens.
+func slugify(name string) string {
+	name = sanitizeFilename(name)
+	name = slugReplacements.Replace(name)
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // Drop combining accents left over from decomposition
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	slug := strings.Trim(slugSeparators.ReplaceAllString(b.String(), "-"), "-")
+	if slug == "" {
+		return "output"
+	}
+	return slug
+}
+
+// uniqueOutput returns path, or a free "_N" variant of it when the file
+// already exists and -overwrite wasn't given.
+func uniqueOutput(cfg Config, path string) string {
+	if cfg.Overwrite || cfg.DryRun {
+		return path
+	}
+	unique := ensureUniqueFilename(path)
+	if unique != path {
+		warnf("Warning: '%s' already exists, writing '%s' instead (use -overwrite to replace it).\n", path, unique)
+	}
+	return unique
+}
+
+func ensureUniqueFilename(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+
+	for i := 1; ; i++ {
+		newPath := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if _, err := os.Stat(newPath); os.IsNotExist(err) {
+			return newPath
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package mutecut
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// manifestInterval is how often (in bytes) the partial-download manifest is
+// refreshed while downloading.
+const manifestInterval = 4 << 20
+
+// partManifest sits next to a .part file and records what it is a partial
+// download of, so a later run can tell whether it is safe to resume.
+type partManifest struct {
+	URL   string `json:"url"`
+	Itag  int    `json:"itag"`
+	Bytes int64  `json:"bytes"`
+}
+
+// downloadRetries is how many times a download that drops mid-way is
+// resumed before giving up.
+const downloadRetries = 3
+
+var errDownloadInterrupted = errors.New("download interrupted")
+
+// downloadWithResume downloads format into outputFile via outputFile.part,
+// renaming only once the download completes. If an earlier run left a
+// matching partial behind it picks up where that one stopped; a partial
+// from a different URL or format is discarded. A connection that drops
+// part-way is resumed a few times before the error is returned.
+func downloadWithResume(client *youtube.Client, video *youtube.Video, format *youtube.Format, url, outputFile string, opts downloadOptions) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = downloadPart(client, video, format, url, outputFile, opts)
+		if err == nil || !errors.Is(err, errDownloadInterrupted) || attempt > downloadRetries {
+			return err
+		}
+		if opts.context().Err() != nil {
+			return err // Cancelled, not a dropped connection
+		}
+		logf("%v; resuming (retry %d/%d)...\n", err, attempt, downloadRetries)
+		time.Sleep(time.Duration(attempt) * 2 * time.Second)
+	}
+}
+
+// downloadPart makes one attempt at finishing outputFile.part.
+func downloadPart(client *youtube.Client, video *youtube.Video, format *youtube.Format, url, outputFile string, opts downloadOptions) error {
+	partFile := outputFile + ".part"
+	manifestFile := partFile + ".json"
+	manifest := partManifest{URL: url, Itag: format.ItagNo}
+
+	offset := resumeOffset(partFile, manifestFile, manifest)
+	if offset > 0 && offset == format.ContentLength {
+		// An earlier run got every byte but stopped before the rename
+		return finishPart(partFile, manifestFile, outputFile)
+	}
+
+	var stream io.ReadCloser
+	var err error
+	if offset > 0 {
+		stream, err = openRangedStream(opts.context(), client, video, format, offset)
+		if err == errRangeComplete {
+			if format.ContentLength <= 0 {
+				return finishPart(partFile, manifestFile, outputFile)
+			}
+			err = errRangeUnsupported // Known length and not it: start over
+		}
+		if err == errRangeUnsupported {
+			logln("Server ignored the resume request, starting over.")
+			offset = 0
+			err = nil
+		}
+	}
+	if err == nil && stream == nil {
+		stream, _, err = client.GetStreamContext(opts.context(), video, format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get stream: %w", err)
+	}
+	defer stream.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		logf("Resuming download at %.1f MB\n", float64(offset)/(1024*1024))
+	}
+	file, err := os.OpenFile(partFile, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	manifest.Bytes = offset
+	writeManifest(manifestFile, manifest)
+
+	w := &manifestWriter{w: file, path: manifestFile, manifest: manifest}
+	progress := newDownloadProgress(stream, offset, format.ContentLength, opts.Verbose)
+	_, err = io.Copy(w, progress)
+	progress.finish()
+	closeErr := file.Close()
+	writeManifest(manifestFile, w.manifest)
+	if err != nil {
+		return fmt.Errorf("%w (partial kept, re-run to resume): %v", errDownloadInterrupted, err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write video: %w", closeErr)
+	}
+
+	return finishPart(partFile, manifestFile, outputFile)
+}
+
+// finishPart moves a completed partial download into place.
+func finishPart(partFile, manifestFile, outputFile string) error {
+	if err := os.Rename(partFile, outputFile); err != nil {
+		return fmt.Errorf("failed to finalize download: %w", err)
+	}
+	os.Remove(manifestFile)
+	return nil
+}
+
+// finishedDownload reports whether path already holds a complete copy of
+// format, left by an earlier run.
+func finishedDownload(path string, format *youtube.Format) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+		return false
+	}
+	return format.ContentLength <= 0 || info.Size() == format.ContentLength
+}
+
+// resumeOffset returns how many bytes of partFile can be kept, removing a
+// stale partial that doesn't belong to this download.
+func resumeOffset(partFile, manifestFile string, want partManifest) int64 {
+	info, err := os.Stat(partFile)
+	if err != nil {
+		return 0
+	}
+
+	var have partManifest
+	data, err := os.ReadFile(manifestFile)
+	if err == nil {
+		err = json.Unmarshal(data, &have)
+	}
+	if err != nil || have.URL != want.URL || have.Itag != want.Itag {
+		logf("Removing stale partial download: %s\n", partFile)
+		os.Remove(partFile)
+		os.Remove(manifestFile)
+		return 0
+	}
+	// The file itself is the source of truth; the manifest may lag behind it
+	return info.Size()
+}
+
+var (
+	errRangeUnsupported = errors.New("range requests not supported")
+	// The server has nothing past the offset: the partial is already whole
+	errRangeComplete = errors.New("nothing left to download")
+)
+
+// openRangedStream requests the stream from offset onward.
+func openRangedStream(ctx context.Context, client *youtube.Client, video *youtube.Video, format *youtube.Format, offset int64) (io.ReadCloser, error) {
+	url, err := client.GetStreamURLContext(ctx, video, format)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil, errRangeUnsupported
+		}
+		if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			return nil, errRangeComplete
+		}
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func writeManifest(path string, m partManifest) {
+	data, _ := json.Marshal(m)
+	os.WriteFile(path, data, 0644)
+}
+
+// manifestWriter counts bytes as they're written and refreshes the manifest
+// every manifestInterval bytes.
+type manifestWriter struct {
+	w         io.Writer
+	path      string
+	manifest  partManifest
+	sinceSave int64
+}
+
+func (m *manifestWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	m.manifest.Bytes += int64(n)
+	m.sinceSave += int64(n)
+	if m.sinceSave >= manifestInterval {
+		writeManifest(m.path, m.manifest)
+		m.sinceSave = 0
+	}
+	return n, err
+}